@@ -0,0 +1,265 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/skill-vendor/skv/internal/dirhash"
+	"github.com/skill-vendor/skv/internal/fsutil"
+	"github.com/skill-vendor/skv/internal/lock"
+	"github.com/skill-vendor/skv/internal/spec"
+)
+
+// adoptSource is a hand-copied skill directory found in a tool directory.
+type adoptSource struct {
+	tool     agentTool
+	path     string
+	checksum string
+}
+
+// adoptCandidate groups the copies of one skill found across tool directories.
+type adoptCandidate struct {
+	name    string
+	sources []adoptSource
+}
+
+// runAdopt initializes skv and imports every unmanaged skill found in the
+// known tool directories as a local entry. Identical copies in several tools
+// become one skill; copies with the same name but different content are
+// reported and nothing is changed. Any failure rolls back all changes.
+func runAdopt(repoRoot string) error {
+	tools, err := resolveTools(&spec.Spec{})
+	if err != nil {
+		return err
+	}
+	candidates, err := scanAdoptable(repoRoot, tools)
+	if err != nil {
+		return err
+	}
+
+	for _, candidate := range candidates {
+		if err := checkSkillMD(candidate.name, candidate.sources[0].path, true); err != nil {
+			return fmt.Errorf("%w; nothing was adopted", err)
+		}
+	}
+
+	conflicts := 0
+	for _, candidate := range candidates {
+		if sums := distinctChecksums(candidate.sources); len(sums) > 1 {
+			conflicts++
+			var parts []string
+			for _, source := range candidate.sources {
+				parts = append(parts, fmt.Sprintf("%s (%s)", relPath(repoRoot, source.path), shortHash(source.checksum)))
+			}
+			globalOutput.Error("conflict: %s differs between %s", candidate.name, strings.Join(parts, ", "))
+		}
+		if _, err := os.Stat(activeScope.vendorPath(repoRoot, candidate.name)); err == nil {
+			conflicts++
+			globalOutput.Error("conflict: %s is already vendored at %s", candidate.name, relPath(repoRoot, activeScope.vendorPath(repoRoot, candidate.name)))
+		}
+	}
+	if conflicts > 0 {
+		return fmt.Errorf("found %d conflict(s); nothing was adopted", conflicts)
+	}
+
+	var undo []func() error
+	rollback := func() {
+		for i := len(undo) - 1; i >= 0; i-- {
+			_ = undo[i]()
+		}
+	}
+	if err := adoptAll(repoRoot, tools, candidates, &undo); err != nil {
+		rollback()
+		return err
+	}
+
+	for _, candidate := range candidates {
+		var paths []string
+		for _, source := range candidate.sources {
+			paths = append(paths, relPath(repoRoot, source.path))
+		}
+		globalOutput.Info("Adopted %s from %s", candidate.name, strings.Join(paths, ", "))
+	}
+	globalOutput.Success("Initialized skv in %s with %d adopted skill(s)", activeScope.describe(), len(candidates))
+	return nil
+}
+
+// adoptAll performs the adoption, recording how to undo each step.
+func adoptAll(repoRoot string, tools []agentTool, candidates []adoptCandidate, undo *[]func() error) error {
+	vendorRoot := activeScope.vendorRoot(repoRoot)
+	if err := ensureDirUndo(vendorRoot, undo); err != nil {
+		return err
+	}
+	if err := ensureDirUndo(filepath.Dir(activeScope.specPath()), undo); err != nil {
+		return err
+	}
+
+	backup, err := os.MkdirTemp(repoRoot, ".skv-adopt-")
+	if err != nil {
+		return err
+	}
+	*undo = append(*undo, func() error { return os.RemoveAll(backup) })
+
+	specData := &spec.Spec{Skills: []spec.SkillEntry{}}
+	lockData := &lock.Lock{Skills: []lock.Skill{}}
+	hashOpts := checksumOptions(specData)
+	for _, candidate := range candidates {
+		vendorPath := activeScope.vendorPath(repoRoot, candidate.name)
+		localPath := relPath(repoRoot, vendorPath)
+		if localPath == ".." || strings.HasPrefix(localPath, "../") {
+			return fmt.Errorf("cannot adopt: vendor directory %s is outside %s", vendorPath, repoRoot)
+		}
+
+		if err := copyDirAtomic(candidate.sources[0].path, vendorPath); err != nil {
+			return err
+		}
+		*undo = append(*undo, func() error { return os.RemoveAll(vendorPath) })
+
+		checksum, files, err := hashSkillDir(vendorPath, hashOpts)
+		if err != nil {
+			return err
+		}
+		skill := spec.SkillEntry{Name: candidate.name, Local: "./" + localPath}
+		entry := lock.Skill{
+			Name:     candidate.name,
+			Local:    skill.Local,
+			Checksum: checksum,
+			Files:    files,
+			License:  detectLicense(vendorPath, repoRoot),
+			Metadata: skillMetadata(vendorPath),
+		}
+		specData.Skills = append(specData.Skills, skill)
+		lockData.Skills = append(lockData.Skills, entry)
+
+		// Move the hand-copied directories aside so links can take their place.
+		for _, source := range candidate.sources {
+			saved := filepath.Join(backup, source.tool.Name, candidate.name)
+			if err := fsutil.EnsureDir(filepath.Dir(saved)); err != nil {
+				return err
+			}
+			if err := os.Rename(source.path, saved); err != nil {
+				return err
+			}
+			*undo = append(*undo, func() error { return os.Rename(saved, source.path) })
+		}
+
+		*undo = append(*undo, func() error {
+			for _, tool := range tools {
+				if _, err := removeManagedLink(repoRoot, candidate.name, filepath.Join(repoRoot, tool.Dir, candidate.name)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err := linkSkill(repoRoot, skill, entry, tools, hashOpts); err != nil {
+			return err
+		}
+	}
+
+	if err := spec.Write(activeScope.specPath(), specData); err != nil {
+		return err
+	}
+	*undo = append(*undo, func() error { return os.Remove(activeScope.specPath()) })
+	if err := lock.Write(activeScope.lockPath(), lockData); err != nil {
+		return err
+	}
+
+	return os.RemoveAll(backup)
+}
+
+// scanAdoptable finds real skill directories in the tool directories, skipping
+// symlinks and skv-managed copies. Candidates are sorted by name.
+func scanAdoptable(repoRoot string, tools []agentTool) ([]adoptCandidate, error) {
+	byName := make(map[string]*adoptCandidate)
+	for _, tool := range tools {
+		dir := filepath.Join(repoRoot, tool.Dir)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			info, err := os.Lstat(path)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				continue
+			}
+			if marker, err := readLinkMarker(path); err != nil || marker != nil {
+				continue
+			}
+			if err := ensureSkill(path); err != nil {
+				globalOutput.Info("Skipping %s: %v", relPath(repoRoot, path), err)
+				continue
+			}
+			if err := validateSkillDir(path); err != nil {
+				return nil, fmt.Errorf("cannot adopt %s: %w", relPath(repoRoot, path), err)
+			}
+			checksum, err := hashDirWithTimeout(path, dirhash.Options{})
+			if err != nil {
+				return nil, err
+			}
+
+			candidate, ok := byName[entry.Name()]
+			if !ok {
+				candidate = &adoptCandidate{name: entry.Name()}
+				byName[entry.Name()] = candidate
+			}
+			candidate.sources = append(candidate.sources, adoptSource{tool: tool, path: path, checksum: checksum})
+		}
+	}
+
+	candidates := make([]adoptCandidate, 0, len(byName))
+	for _, candidate := range byName {
+		candidates = append(candidates, *candidate)
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].name < candidates[j].name })
+	return candidates, nil
+}
+
+func distinctChecksums(sources []adoptSource) map[string]struct{} {
+	sums := make(map[string]struct{}, len(sources))
+	for _, source := range sources {
+		sums[source.checksum] = struct{}{}
+	}
+	return sums
+}
+
+// ensureDirUndo creates dir and, if it did not exist, records its removal.
+func ensureDirUndo(dir string, undo *[]func() error) error {
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+	// Undo from the outermost directory that is created.
+	top := dir
+	for {
+		parent := filepath.Dir(top)
+		if parent == top {
+			break
+		}
+		if _, err := os.Stat(parent); err == nil {
+			break
+		}
+		top = parent
+	}
+	if err := fsutil.EnsureDir(dir); err != nil {
+		return err
+	}
+	*undo = append(*undo, func() error { return os.RemoveAll(top) })
+	return nil
+}
+
+func shortHash(sum string) string {
+	if len(sum) > 12 {
+		return sum[:12]
+	}
+	return sum
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/skill-vendor/skv/internal/audit"
+	"github.com/skill-vendor/skv/internal/spec"
+)
+
+type auditOptions struct {
+	json   bool
+	failOn string
+}
+
+type auditFinding struct {
+	Skill string `json:"skill"`
+	audit.Finding
+	Allowed bool `json:"allowed,omitempty"`
+}
+
+func (f auditFinding) String() string {
+	return fmt.Sprintf("%s: %s", f.Skill, f.Finding)
+}
+
+// runAudit scans the named skills, or every skill in the spec, for risky
+// content. Remote skills are scanned as vendored; local skills at their
+// source. It fails if a finding not allowed by the spec is at or above the
+// --fail-on severity, which defaults to audit.block and then to low.
+func runAudit(names []string, opts auditOptions) error {
+	repoRoot, err := activeScope.root()
+	if err != nil {
+		return err
+	}
+	specData, err := loadSpec()
+	if err != nil {
+		return err
+	}
+
+	thresholdName := opts.failOn
+	if thresholdName == "" && specData.Audit != nil {
+		thresholdName = specData.Audit.Block
+	}
+	threshold := audit.Low
+	if thresholdName != "" {
+		if threshold, err = audit.ParseSeverity(thresholdName); err != nil {
+			if opts.failOn != "" {
+				return usageErrorf("--fail-on: %v", err)
+			}
+			return err
+		}
+	}
+
+	targets := specData.Skills
+	if len(names) > 0 {
+		targets = nil
+		for _, name := range names {
+			skill, ok := findSkill(specData, name)
+			if !ok {
+				return fmt.Errorf("skill %q not found in spec", name)
+			}
+			targets = append(targets, skill)
+		}
+	}
+
+	var findings []auditFinding
+	for _, skill := range targets {
+		dir := activeScope.vendorPath(repoRoot, skill.Name)
+		if skill.Local != "" {
+			if dir, err = resolveLocalPath(repoRoot, skill.Local); err != nil {
+				return err
+			}
+		}
+		if err := ensureSkill(dir); err != nil {
+			return fmt.Errorf("%s: %w; run skv sync", skill.Name, err)
+		}
+		f, err := skillFilter(skill, dir)
+		if err != nil {
+			return err
+		}
+		scanned, err := audit.Scan(dir, f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", skill.Name, err)
+		}
+		for _, finding := range scanned {
+			findings = append(findings, auditFinding{
+				Skill:   skill.Name,
+				Finding: finding,
+				Allowed: auditAllowed(specData.Audit, skill.Name, finding),
+			})
+		}
+	}
+
+	blocking, allowed := 0, 0
+	for _, finding := range findings {
+		switch {
+		case finding.Allowed:
+			allowed++
+		case finding.Severity >= threshold:
+			blocking++
+		}
+	}
+	if opts.json {
+		if findings == nil {
+			findings = []auditFinding{}
+		}
+		data, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else {
+		for _, finding := range findings {
+			switch {
+			case finding.Allowed:
+			case finding.Severity >= threshold:
+				globalOutput.Error("%s", finding)
+			default:
+				globalOutput.Warn("%s", finding)
+			}
+		}
+	}
+	if blocking > 0 {
+		return fmt.Errorf("audit found %d finding(s) at or above %s severity", blocking, threshold)
+	}
+	if !opts.json {
+		if allowed > 0 {
+			globalOutput.Info("%d finding(s) allowed by skv.cue", allowed)
+		}
+		globalOutput.Success("Audited %d skill(s)", len(targets))
+	}
+	return nil
+}
+
+// auditAllowed reports whether config allows finding in the skill name.
+func auditAllowed(config *spec.Audit, name string, finding audit.Finding) bool {
+	if config == nil {
+		return false
+	}
+	for _, allow := range config.Allow {
+		if allow.Skill == name && allow.Rule == finding.Rule &&
+			(allow.Path == "" || audit.HasPathPrefix(finding.Path, allow.Path)) {
+			return true
+		}
+	}
+	return false
+}
+
+// auditGate scans skill content about to be vendored from dir. Findings the
+// spec does not allow are reported, and those at or above config.Block stop
+// the skill from being vendored. It does nothing unless config sets Block.
+func auditGate(name, dir string, config *spec.Audit) error {
+	if config == nil || config.Block == "" {
+		return nil
+	}
+	threshold, err := audit.ParseSeverity(config.Block)
+	if err != nil {
+		return fmt.Errorf("audit.block: %w", err)
+	}
+	findings, err := audit.Scan(dir, nil)
+	if err != nil {
+		return err
+	}
+	blocking := 0
+	for _, finding := range findings {
+		if auditAllowed(config, name, finding) {
+			continue
+		}
+		if finding.Severity >= threshold {
+			blocking++
+			globalOutput.Error("%s: %s", name, finding)
+		} else {
+			globalOutput.Warn("%s: %s", name, finding)
+		}
+	}
+	if blocking > 0 {
+		return fmt.Errorf("audit blocked %q: %d finding(s) at or above %s severity; fix them or allow them under audit in skv.cue", name, blocking, threshold)
+	}
+	return nil
+}
@@ -36,7 +36,7 @@ func Execute() error {
 }
 
 func newRootCmd() *cobra.Command {
-	var quiet bool
+	var quiet, global bool
 
 	cmd := &cobra.Command{
 		Use:   "skv",
@@ -50,8 +50,17 @@ func newRootCmd() *cobra.Command {
   skv list
   skv status
 `),
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			globalOutput.SetQuiet(quiet)
+			activeScope = &scope{}
+			if global {
+				s, err := newGlobalScope()
+				if err != nil {
+					return err
+				}
+				activeScope = s
+			}
+			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
@@ -67,6 +76,7 @@ func newRootCmd() *cobra.Command {
 	cmd.CompletionOptions.DisableDefaultCmd = true
 
 	cmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "suppress non-error output")
+	cmd.PersistentFlags().BoolVarP(&global, "global", "g", false, "operate on user-scope skills linked into agent directories under $HOME")
 
 	cmd.AddCommand(newInitCmd())
 	cmd.AddCommand(newAddCmd())
@@ -74,10 +84,22 @@ func newRootCmd() *cobra.Command {
 	cmd.AddCommand(newUpdateCmd())
 	cmd.AddCommand(newVerifyCmd())
 	cmd.AddCommand(newImportCmd())
+	cmd.AddCommand(newLinkUpstreamCmd())
+	cmd.AddCommand(newEjectCmd())
+	cmd.AddCommand(newDiffCmd())
+	cmd.AddCommand(newPatchCmd())
+	cmd.AddCommand(newLintCmd())
+	cmd.AddCommand(newAuditCmd())
+	cmd.AddCommand(newLicensesCmd())
+	cmd.AddCommand(newSBOMCmd())
+	cmd.AddCommand(newNoticesCmd())
 	cmd.AddCommand(newVersionCmd())
 	cmd.AddCommand(newListCmd())
 	cmd.AddCommand(newRemoveCmd())
 	cmd.AddCommand(newStatusCmd())
+	cmd.AddCommand(newTidyCmd())
+	cmd.AddCommand(newLockCmd())
+	cmd.AddCommand(newInstallMergeDriverCmd())
 
 	return cmd
 }
@@ -94,23 +116,32 @@ func newVersionCmd() *cobra.Command {
 }
 
 func newInitCmd() *cobra.Command {
+	var adopt bool
 	cmd := &cobra.Command{
-		Use:     "init",
-		Short:   "Initialize skv in the current repo",
-		Long:    "Create skv.cue, skv.lock, and .skv/skills in the current repository.",
-		Example: "  skv init",
+		Use:   "init",
+		Short: "Initialize skv in the current repo",
+		Long: "Create skv.cue, skv.lock, and .skv/skills in the current repository. " +
+			"With --global, create the user-scope spec and lock under $XDG_CONFIG_HOME/skv " +
+			"and the vendor directory under $XDG_DATA_HOME/skv. " +
+			"With --adopt, import the skills already copied into tool directories as local entries; " +
+			"identical copies are merged, and nothing changes if copies with the same name differ.",
+		Example: strings.TrimSpace(`
+  skv init
+  skv init --adopt
+`),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 0 {
 				return usageErrorf("init does not accept arguments")
 			}
-			return runInit()
+			return runInit(initOptions{adopt: adopt})
 		},
 	}
+	cmd.Flags().BoolVar(&adopt, "adopt", false, "import existing skills from tool directories")
 	return cmd
 }
 
 func newAddCmd() *cobra.Command {
-	var name string
+	var name, namespace string
 	var noSync bool
 	cmd := &cobra.Command{
 		Use:   "add <repo>[#ref][:path]",
@@ -122,16 +153,18 @@ func newAddCmd() *cobra.Command {
   skv add https://github.com/acme/skill-pack:skills/skill-foo
   skv add https://github.com/acme/skill-pack#v1.2.3:skills/skill-foo
   skv add https://github.com/acme/skill-pack:skills/skill-foo --name release-notes
+  skv add https://github.com/acme/skill-pack:skills/code-review --namespace acme
   skv add https://github.com/acme/skill-foo --no-sync
 `),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 1 {
 				return usageErrorf("add requires <repo>[#ref][:path]")
 			}
-			return runAdd(args[0], addOptions{name: name, noSync: noSync})
+			return runAdd(args[0], addOptions{name: name, namespace: namespace, noSync: noSync})
 		},
 	}
 	cmd.Flags().StringVar(&name, "name", "", "override skill name")
+	cmd.Flags().StringVar(&namespace, "namespace", "", "install as <namespace>--<name> to avoid collisions")
 	cmd.Flags().BoolVar(&noSync, "no-sync", false, "only add to skv.cue, don't fetch")
 	return cmd
 }
@@ -200,38 +233,126 @@ func newUpdateCmd() *cobra.Command {
 }
 
 func newVerifyCmd() *cobra.Command {
+	var format, allowedSigners string
+	var requireSignature bool
 	cmd := &cobra.Command{
-		Use:     "verify",
-		Short:   "Verify vendored skills match skv.lock",
-		Long:    "Verify that vendored skill content matches the checksums recorded in skv.lock.",
-		Example: "  skv verify",
+		Use:   "verify",
+		Short: "Verify spec, lock, vendored skills, and links agree",
+		Long: "Verify that skv.lock matches skv.cue, that vendored skill content matches the " +
+			"checksums recorded in skv.lock, that tool links point at the vendored skills, and that " +
+			"skills satisfy the policy in skv.cue, skv.policy.cue, or $SKV_POLICY, and that notices written by " +
+			"skv notices are up to date. " +
+			"With --require-signature, also check that skv.lock.sig is a valid signature of skv.lock by a key in the " +
+			"allowed-signers file given with --allowed-signers or $SKV_LOCK_SIGNERS; verify.lockKeys is not trusted here, " +
+			"since a change to the lock can edit it too. " +
+			"Every problem is reported, grouped by category (spec-lock, lock-vendor, vendor-link, policy, notices, lock-signature).",
+		Example: strings.TrimSpace(`
+  skv verify
+  skv verify --format sarif > skv.sarif
+  skv verify --format junit > skv-junit.xml
+  skv verify --require-signature --allowed-signers .github/allowed_signers
+`),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 0 {
 				return usageErrorf("verify does not accept arguments")
 			}
-			return runVerify()
+			return runVerify(reportOptions{
+				format:           format,
+				requireSignature: requireSignature,
+				signature:        lockSignatureOptions{allowedSigners: allowedSigners, external: true},
+			})
 		},
 	}
+	cmd.Flags().StringVar(&format, "format", formatText, "output format: text, json, sarif, or junit")
+	cmd.Flags().BoolVar(&requireSignature, "require-signature", false, "fail unless skv.lock is signed by an allowed key")
+	cmd.Flags().StringVar(&allowedSigners, "allowed-signers", "", "SSH allowed-signers file from outside the change to check the lock signature against")
 	return cmd
 }
 
 func newImportCmd() *cobra.Command {
+	var detectUpstream string
 	cmd := &cobra.Command{
 		Use:   "import <agentDir>/<skill>",
 		Short: "Import a local skill into .skv/skills",
 		Long: "Move a local skill into .skv/skills, add it as a local entry in skv.cue, " +
-			"and link it into supported tool directories.",
+			"and link it into supported tool directories. " +
+			"With --detect-upstream, first search the given repo's history for a commit whose content " +
+			"matches the skill and record it as a remote pinned to that commit; on a near-miss, " +
+			"print the differences and import nothing.",
 		Example: strings.TrimSpace(`
   skv import .codex/skills/skill-foo
   skv import .claude/skills/skill-foo
+  skv import .claude/skills/skill-foo --detect-upstream https://github.com/acme/skill-pack:skills/skill-foo
 `),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 1 {
 				return usageErrorf("import requires <agentDir>/<skill>")
 			}
-			return runImport(args[0])
+			return runImport(args[0], importOptions{detectUpstream: detectUpstream})
+		},
+	}
+	cmd.Flags().StringVar(&detectUpstream, "detect-upstream", "", "link to the matching commit in `<repo>[#ref][:path]`")
+	return cmd
+}
+
+func newLinkUpstreamCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "link-upstream <name> <repo>[#ref][:path]",
+		Short: "Convert a local skill into a pinned remote",
+		Long: "Search the history of <repo> (at ref, or its default branch) for a commit whose " +
+			"subtree matches the local skill's content. On a match, rewrite the skill in skv.cue " +
+			"and skv.lock as a remote pinned to that commit. Without :path, any directory may match. " +
+			"On a near-miss, print how the local copy differs from the closest upstream content.",
+		Example: strings.TrimSpace(`
+  skv link-upstream skill-foo https://github.com/acme/skill-foo
+  skv link-upstream release-notes https://github.com/acme/skill-pack#main:skills/release-notes
+`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return usageErrorf("link-upstream requires <name> <repo>[#ref][:path]")
+			}
+			return runLinkUpstream(args[0], args[1])
+		},
+	}
+	return cmd
+}
+
+func newEjectCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "eject <name>",
+		Short: "Fork a vendored remote skill into a local skill",
+		Long: "Convert a remote skill into a local entry in skv.cue that keeps the vendored files in .skv/skills. " +
+			"The repo, commit, and license it was forked from are kept in skv.lock under forkedFrom, " +
+			"so skv diff --upstream can show how the fork diverged.",
+		Example: strings.TrimSpace(`
+  skv eject skill-foo
+`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return usageErrorf("eject requires a skill name")
+			}
+			return runEject(args[0])
+		},
+	}
+	return cmd
+}
+
+func newDiffCmd() *cobra.Command {
+	var upstream bool
+	cmd := &cobra.Command{
+		Use:   "diff --upstream [name...]",
+		Short: "Show how ejected skills diverged from upstream",
+		Long: "With --upstream, print a patch from the upstream commit each ejected skill was forked from " +
+			"to its current local content. Without names, every ejected skill is shown.",
+		Example: strings.TrimSpace(`
+  skv diff --upstream
+  skv diff --upstream skill-foo
+`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDiff(args, diffOptions{upstream: upstream})
 		},
 	}
+	cmd.Flags().BoolVar(&upstream, "upstream", false, "compare against the commit the skill was forked from")
 	return cmd
 }
 
@@ -279,18 +400,310 @@ func newRemoveCmd() *cobra.Command {
 }
 
 func newStatusCmd() *cobra.Command {
+	var format string
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show status of installed skills",
-		Long:  "Show the state and drift for each skill (ok, modified, missing).",
+		Long:  "Show the state and drift for each skill (ok, modified, missing, extra).",
 		Example: strings.TrimSpace(`
   skv status
+  skv status --format json
 `),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 0 {
 				return usageErrorf("status does not accept arguments")
 			}
-			return runStatus()
+			return runStatus(reportOptions{format: format})
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", formatText, "output format: text, json, sarif, or junit")
+	return cmd
+}
+
+func newTidyCmd() *cobra.Command {
+	var dryRun bool
+	var jsonOutput bool
+	cmd := &cobra.Command{
+		Use:   "tidy",
+		Short: "Prune lock entries, vendored dirs, and links not in skv.cue",
+		Long: "Reconcile skv.cue, skv.lock, .skv/skills, and tool directories. " +
+			"Removes lock entries and vendored directories no spec entry references, and " +
+			"symlinks into .skv/skills that are dangling or no longer wanted. " +
+			"Unmanaged skills in tool directories are never touched.",
+		Example: strings.TrimSpace(`
+  skv tidy
+  skv tidy --dry-run
+  skv tidy --dry-run --json
+`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return usageErrorf("tidy does not accept arguments")
+			}
+			return runTidy(tidyOptions{dryRun: dryRun, json: jsonOutput})
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "report drift without changing anything")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output findings as JSON")
+	return cmd
+}
+
+func newAuditCmd() *cobra.Command {
+	var opts auditOptions
+	cmd := &cobra.Command{
+		Use:   "audit [name...]",
+		Short: "Scan skills for risky content",
+		Long: "Scan vendored remote skills and local skill sources for content that is risky to hand to an agent " +
+			"with shell access: embedded secrets and tokens, downloads piped into a shell, large base64 blobs, " +
+			"hidden Unicode and bidi control characters, prompt-injection phrases, and executable binaries. " +
+			"Reviewed findings can be accepted with audit.allow in skv.cue. Exits non-zero if a finding that is " +
+			"not allowed is at or above --fail-on (default: audit.block in skv.cue, otherwise low). " +
+			"When audit.block is set, sync and update run the same scan and refuse to vendor blocked content.",
+		Example: strings.TrimSpace(`
+  skv audit
+  skv audit skill-foo
+  skv audit --fail-on high --json
+`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAudit(args, opts)
+		},
+	}
+	cmd.Flags().BoolVar(&opts.json, "json", false, "output findings as JSON")
+	cmd.Flags().StringVar(&opts.failOn, "fail-on", "", "lowest severity that fails the audit (low, medium, high)")
+	return cmd
+}
+
+func newLicensesCmd() *cobra.Command {
+	var opts licensesOptions
+	cmd := &cobra.Command{
+		Use:   "licenses",
+		Short: "Report the license of each skill",
+		Long: "Show the SPDX license recorded in skv.lock for each skill, with the confidence of the match and " +
+			"the file it was found in. Licenses are identified at sync time from an SPDX-License-Identifier " +
+			"header or by comparing the license text against an embedded corpus of common licenses; text " +
+			"that matches none of them closely enough is reported as unknown.",
+		Example: strings.TrimSpace(`
+  skv licenses
+  skv licenses --json
+`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return usageErrorf("licenses does not accept arguments")
+			}
+			return runLicenses(opts)
+		},
+	}
+	cmd.Flags().BoolVar(&opts.json, "json", false, "output as JSON")
+	return cmd
+}
+
+func newSBOMCmd() *cobra.Command {
+	opts := sbomOptions{format: "cyclonedx"}
+	cmd := &cobra.Command{
+		Use:   "sbom",
+		Short: "Export a software bill of materials for vendored skills",
+		Long: "Generate a CycloneDX 1.5 or SPDX 2.3 JSON SBOM from skv.lock. Each remote skill is a " +
+			"third-party component with its repo URL, commit, path, checksum, and detected license; each local " +
+			"skill is a first-party component, with the upstream it was forked from, if any. " +
+			"Set SOURCE_DATE_EPOCH to fix the timestamp for reproducible output.",
+		Example: strings.TrimSpace(`
+  skv sbom
+  skv sbom --format spdx --output skills.spdx.json
+`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return usageErrorf("sbom does not accept arguments")
+			}
+			return runSBOM(opts)
+		},
+	}
+	cmd.Flags().StringVar(&opts.format, "format", opts.format, "SBOM format (cyclonedx, spdx)")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "write the SBOM to a file instead of stdout")
+	return cmd
+}
+
+func newNoticesCmd() *cobra.Command {
+	var opts noticesOptions
+	cmd := &cobra.Command{
+		Use:   "notices",
+		Short: "Write the license and NOTICE texts of third-party skills",
+		Long: "Collect the full license text and any NOTICE files of every vendored remote skill, and of the " +
+			"upstream of every ejected skill, into THIRD_PARTY_NOTICES.md, or the output set under notices in " +
+			"skv.cue. An output ending in / is a directory with one file per skill. Texts are read from the " +
+			"vendored copy when it has them and from the repo at the locked commit otherwise. " +
+			"skv verify fails when the generated notices no longer match skv.lock.",
+		Example: strings.TrimSpace(`
+  skv notices
+  skv notices --output licenses/
+`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return usageErrorf("notices does not accept arguments")
+			}
+			return runNotices(opts)
+		},
+	}
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "file or directory (ending in /) to write instead of the configured output")
+	return cmd
+}
+
+func newLintCmd() *cobra.Command {
+	var jsonOutput bool
+	cmd := &cobra.Command{
+		Use:   "lint [name...]",
+		Short: "Check skills for common quality problems",
+		Long: "Run lint rules over vendored remote skills and local skill sources: SKILL.md frontmatter, " +
+			"a name that differs from the installed name, SKILL.md size against a token budget, " +
+			"relative links that do not resolve, referenced scripts that are missing or not executable, " +
+			"and files that are not valid UTF-8. Rules can be turned off with lint.disable in skv.cue. " +
+			"Exits non-zero if any rule reports an error.",
+		Example: strings.TrimSpace(`
+  skv lint
+  skv lint skill-foo
+  skv lint --json
+`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLint(args, lintOptions{json: jsonOutput})
+		},
+	}
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output findings as JSON")
+	return cmd
+}
+
+func newPatchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "patch",
+		Short: "Maintain local patches to vendored skills",
+		Long: "Patches listed in a skill's patches field are applied in order on top of the fetched content " +
+			"before it is hashed, so small local tweaks survive sync and update.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return usageErrorf("unknown patch subcommand %q", args[0])
+			}
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(newPatchCreateCmd())
+	return cmd
+}
+
+func newPatchCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Turn local edits to a vendored skill into a patch",
+		Long: "Diff the vendored copy of a remote skill against its locked upstream content with existing patches applied, " +
+			"write the difference to patches/<name>/NNNN.patch, add it to the skill's patches in skv.cue, " +
+			"and update skv.lock.",
+		Example: strings.TrimSpace(`
+  skv patch create skill-foo
+`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return usageErrorf("patch create requires a skill name")
+			}
+			return runPatchCreate(args[0])
+		},
+	}
+	return cmd
+}
+
+func newLockCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lock",
+		Short: "Work with skv.lock directly",
+		Long:  "Low-level commands that operate on skv.lock files.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return usageErrorf("unknown lock subcommand %q", args[0])
+			}
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(newLockMergeCmd())
+	cmd.AddCommand(newLockSignCmd())
+	cmd.AddCommand(newLockVerifySignatureCmd())
+	return cmd
+}
+
+func newLockMergeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "merge <base> <ours> <theirs>",
+		Short: "Three-way merge skv.lock files (git merge driver)",
+		Long: "Merge lock entries by skill name and write the result to <ours>. " +
+			"Exits non-zero only when the same skill changed differently on both sides. " +
+			"Intended to be used as a git merge driver; see skv install-merge-driver.",
+		Example: strings.TrimSpace(`
+  skv lock merge %O %A %B
+`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 3 {
+				return usageErrorf("lock merge requires <base> <ours> <theirs>")
+			}
+			return runLockMerge(args[0], args[1], args[2])
+		},
+	}
+	return cmd
+}
+
+func newLockSignCmd() *cobra.Command {
+	var key string
+	cmd := &cobra.Command{
+		Use:   "sign",
+		Short: "Sign skv.lock with an SSH key",
+		Long: "Write a detached SSH signature of skv.lock to skv.lock.sig, made with ssh-keygen -Y sign " +
+			"over a canonical form of the lock. The key defaults to git config user.signingkey. " +
+			"Re-sign after every change to the lock.",
+		Example: strings.TrimSpace(`
+  skv lock sign
+  skv lock sign --key ~/.ssh/id_ed25519
+`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return usageErrorf("lock sign does not accept arguments")
+			}
+			return runLockSign(lockSignOptions{key: key})
+		},
+	}
+	cmd.Flags().StringVar(&key, "key", "", "SSH private key, or public key held by ssh-agent, to sign with")
+	return cmd
+}
+
+func newLockVerifySignatureCmd() *cobra.Command {
+	var allowedSigners string
+	cmd := &cobra.Command{
+		Use:   "verify-signature",
+		Short: "Check skv.lock.sig against the allowed lock signers",
+		Long: "Check that skv.lock.sig is a signature of the current skv.lock by one of the keys in " +
+			"the SSH allowed-signers file given with --allowed-signers or $SKV_LOCK_SIGNERS, or else in verify.lockKeys.",
+		Example: strings.TrimSpace(`
+  skv lock verify-signature
+  skv lock verify-signature --allowed-signers .github/allowed_signers
+`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return usageErrorf("lock verify-signature does not accept arguments")
+			}
+			return runLockVerifySignature(lockSignatureOptions{allowedSigners: allowedSigners})
+		},
+	}
+	cmd.Flags().StringVar(&allowedSigners, "allowed-signers", "", "SSH allowed-signers file to use instead of verify.lockKeys")
+	return cmd
+}
+
+func newInstallMergeDriverCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "install-merge-driver",
+		Short: "Configure git to merge skv.lock with skv",
+		Long: "Register the skv merge driver in the repository's git config and " +
+			"mark skv.lock with merge=skv and skv.sum with merge=union in .gitattributes.",
+		Example: "  skv install-merge-driver",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return usageErrorf("install-merge-driver does not accept arguments")
+			}
+			if activeScope.global {
+				return usageErrorf("install-merge-driver does not support --global")
+			}
+			return runInstallMergeDriver()
 		},
 	}
 	return cmd
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/skill-vendor/skv/internal/dirhash"
+	"github.com/skill-vendor/skv/internal/lock"
+	"github.com/skill-vendor/skv/internal/spec"
+)
+
+type diffOptions struct {
+	upstream bool
+}
+
+// runEject converts a vendored remote skill into a local one, keeping the
+// vendored files and recording the remote it was forked from in the lock.
+func runEject(name string) error {
+	repoRoot, err := activeScope.root()
+	if err != nil {
+		return err
+	}
+	specData, err := spec.Load(activeScope.specPath())
+	if err != nil {
+		return err
+	}
+	index := -1
+	for i, skill := range specData.Skills {
+		if specData.QualifiedName(skill) == name {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return fmt.Errorf("skill %q not found in spec", name)
+	}
+	skill := specData.Skills[index]
+	if skill.Local != "" {
+		return fmt.Errorf("skill %q is already a local skill", name)
+	}
+
+	lockData, lockMap, err := loadLockRequired(activeScope.lockPath())
+	if err != nil {
+		return err
+	}
+	if err := ensureChecksumMode(specData, lockData); err != nil {
+		return err
+	}
+	hashOpts := checksumOptions(specData)
+
+	locked := skill
+	locked.Name = name
+	if locked.Path, err = cleanSubpath(locked.Path); err != nil {
+		return err
+	}
+	entry, ok := lockMap[name]
+	if !ok || !lockMatchesSpec(entry, locked) {
+		return fmt.Errorf("lock entry for %q does not match spec; run skv sync first", name)
+	}
+
+	vendorPath := activeScope.vendorPath(repoRoot, name)
+	if err := ensureSkill(vendorPath); err != nil {
+		return fmt.Errorf("vendored content for %q is missing; run skv sync first", name)
+	}
+	if err := validateSkillDir(vendorPath); err != nil {
+		return err
+	}
+	localPath := relPath(repoRoot, vendorPath)
+	if localPath == ".." || strings.HasPrefix(localPath, "../") {
+		return fmt.Errorf("cannot eject: vendor directory %s is outside %s", vendorPath, repoRoot)
+	}
+	localPath = "./" + localPath
+
+	// Pin the repo's namespace on the entry so the installed name survives
+	// losing the repo.
+	if skill.Namespace == "" {
+		skill.Namespace = specData.Namespaces[skill.Repo]
+	}
+	skill.Repo, skill.Ref, skill.Path = "", "", ""
+	// The vendored files already have patches and filters applied.
+	skill.Patches = nil
+	skill.Files = nil
+	skill.Local = localPath
+
+	checksum, files, err := hashSkillDir(vendorPath, hashOpts)
+	if err != nil {
+		return err
+	}
+	ejected := lock.Skill{
+		Name:     name,
+		Local:    localPath,
+		Checksum: checksum,
+		Files:    files,
+		License:  detectLicense(vendorPath, repoRoot),
+		Metadata: skillMetadata(vendorPath),
+		ForkedFrom: &lock.Fork{
+			Repo:     entry.Repo,
+			Path:     entry.Path,
+			Ref:      entry.Ref,
+			Commit:   entry.Commit,
+			Checksum: upstreamChecksum(entry),
+			License:  entry.License,
+		},
+	}
+
+	specData.Skills[index] = skill
+	if err := spec.Write(activeScope.specPath(), specData); err != nil {
+		return err
+	}
+	lockMap[name] = ejected
+	var lockSkills []lock.Skill
+	for _, s := range specData.Skills {
+		if e, ok := lockMap[specData.QualifiedName(s)]; ok {
+			lockSkills = append(lockSkills, e)
+		}
+	}
+	sort.Slice(lockSkills, func(i, j int) bool { return lockSkills[i].Name < lockSkills[j].Name })
+	lockData.Normalize = hashOpts.Normalize
+	lockData.Skills = lockSkills
+	if err := lock.Write(activeScope.lockPath(), lockData); err != nil {
+		return err
+	}
+
+	tools, err := resolveTools(specData)
+	if err != nil {
+		return err
+	}
+	linked := skill
+	linked.Name = name
+	if err := linkSkill(repoRoot, linked, ejected, tools, hashOpts); err != nil {
+		return err
+	}
+	globalOutput.Success("Ejected %s to %s (forked from %s)", name, localPath, describeFork(ejected.ForkedFrom))
+	return nil
+}
+
+// runDiff prints how ejected skills diverged from the upstream commit they were
+// forked from. With no names, every ejected skill is shown.
+func runDiff(names []string, opts diffOptions) error {
+	if !opts.upstream {
+		return usageErrorf("diff requires --upstream")
+	}
+	repoRoot, err := activeScope.root()
+	if err != nil {
+		return err
+	}
+	specData, err := loadSpec()
+	if err != nil {
+		return err
+	}
+	_, lockMap, err := loadLockRequired(activeScope.lockPath())
+	if err != nil {
+		return err
+	}
+
+	var targets []spec.SkillEntry
+	if len(names) > 0 {
+		for _, name := range names {
+			skill, ok := findSkill(specData, name)
+			if !ok {
+				return fmt.Errorf("skill %q not found in spec", name)
+			}
+			if entry, ok := lockMap[name]; !ok || entry.ForkedFrom == nil || skill.Local == "" {
+				return fmt.Errorf("skill %q was not ejected from an upstream", name)
+			}
+			targets = append(targets, skill)
+		}
+	} else {
+		for _, skill := range specData.Skills {
+			if entry, ok := lockMap[skill.Name]; ok && entry.ForkedFrom != nil && skill.Local != "" {
+				targets = append(targets, skill)
+			}
+		}
+	}
+	if len(targets) == 0 {
+		globalOutput.Info("No ejected skills")
+		return nil
+	}
+
+	hashOpts := checksumOptions(specData)
+	for _, skill := range targets {
+		fork := lockMap[skill.Name].ForkedFrom
+		localDir, err := resolveLocalPath(repoRoot, skill.Local)
+		if err != nil {
+			return err
+		}
+		patch, err := diffAgainstFork(skill.Name, localDir, fork, hashOpts)
+		if err != nil {
+			return err
+		}
+		if patch == "" {
+			globalOutput.Info("%s: no changes since fork of %s", skill.Name, describeFork(fork))
+			continue
+		}
+		globalOutput.Info("%s: changes since fork of %s", skill.Name, describeFork(fork))
+		fmt.Print(patch)
+	}
+	return nil
+}
+
+// diffAgainstFork returns a patch from the content of the skill name was
+// forked from to localDir.
+func diffAgainstFork(name, localDir string, fork *lock.Fork, hashOpts dirhash.Options) (string, error) {
+	cloneDir, err := cloneLockedCommit(spec.SkillEntry{Name: name, Repo: fork.Repo, Path: fork.Path}, fork.Commit, "")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(cloneDir)
+
+	upstreamTree, err := commitPathTree(cloneDir, fork.Commit, fork.Path)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	localTree, _, err := writeLocalTree(cloneDir, localDir, hashOpts)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	patch, err := diffTrees(cloneDir, upstreamTree, localTree)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return patch, nil
+}
+
+// upstreamChecksum returns the checksum of a locked skill's content as fetched,
+// before any patches.
+func upstreamChecksum(entry lock.Skill) string {
+	if entry.UpstreamChecksum != "" {
+		return entry.UpstreamChecksum
+	}
+	return entry.Checksum
+}
+
+func describeFork(fork *lock.Fork) string {
+	source := fork.Repo
+	if fork.Path != "" {
+		source += ":" + fork.Path
+	}
+	return fmt.Sprintf("%s @ %s", source, lock.ShortCommit(fork.Commit))
+}
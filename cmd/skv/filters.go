@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/skill-vendor/skv/internal/dirhash"
+	"github.com/skill-vendor/skv/internal/filter"
+	"github.com/skill-vendor/skv/internal/spec"
+)
+
+// skillFilter returns the effective file filter for skill vendored from dir:
+// its files globs, with the patterns of dir's .skvignore appended to the
+// excludes. It returns nil when nothing is filtered.
+func skillFilter(skill spec.SkillEntry, dir string) (*filter.Filter, error) {
+	f := &filter.Filter{}
+	if skill.Files != nil {
+		f.Include = slices.Clone(skill.Files.Include)
+		f.Exclude = slices.Clone(skill.Files.Exclude)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, filter.IgnoreFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	f.Exclude = append(f.Exclude, filter.ParseIgnore(data)...)
+	if err := f.Validate(); err != nil {
+		return nil, fmt.Errorf("skill %q: %w", skill.Name, err)
+	}
+	if f.IsEmpty() {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// filteredOut returns the first file under dir that f drops, or "" if f keeps
+// every file.
+func filteredOut(dir string, f *filter.Filter) (string, error) {
+	if f.IsEmpty() {
+		return "", nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), hashTimeout)
+	defer cancel()
+	files, err := dirhash.Files(ctx, dir, dirhash.Options{})
+	if err != nil {
+		return "", err
+	}
+	for _, file := range files {
+		if !f.Keep(file.Path) {
+			return file.Path, nil
+		}
+	}
+	return "", nil
+}
+
+// withFilter returns opts limited to the files f keeps.
+func withFilter(opts dirhash.Options, f *filter.Filter) dirhash.Options {
+	opts.Filter = f
+	return opts
+}
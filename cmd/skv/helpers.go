@@ -9,12 +9,16 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/skill-vendor/skv/internal/dirhash"
+	"github.com/skill-vendor/skv/internal/filter"
 	"github.com/skill-vendor/skv/internal/fsutil"
+	"github.com/skill-vendor/skv/internal/license"
 	"github.com/skill-vendor/skv/internal/lock"
+	"github.com/skill-vendor/skv/internal/skillmd"
 	"github.com/skill-vendor/skv/internal/spec"
 )
 
@@ -24,10 +28,35 @@ const (
 	maxSkillFiles    = 5000
 	maxFileBytes     = 5 * 1024 * 1024
 
+	maxListDescription = 60
+
 	gitTimeout  = 2 * time.Minute
 	hashTimeout = 30 * time.Second
+
+	mergeDriverName      = "skv lock merge driver"
+	mergeDriverCommand   = "skv lock merge %O %A %B"
+	mergeDriverAttribute = "skv.lock merge=skv"
+	// skv.sum only gains lines, so git's union merge keeps both sides'.
+	sumMergeAttribute = "skv.sum merge=union"
 )
 
+// loadSpec loads the active scope's spec with namespaces applied, so each
+// skill's Name is the name it is vendored, locked and linked under.
+func loadSpec() (*spec.Spec, error) {
+	return loadSpecAt(activeScope.specPath())
+}
+
+func loadSpecAt(path string) (*spec.Spec, error) {
+	specData, err := spec.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	for i, skill := range specData.Skills {
+		specData.Skills[i].Name = specData.QualifiedName(skill)
+	}
+	return specData, nil
+}
+
 func loadLockOptional(path string) (*lock.Lock, map[string]lock.Skill, error) {
 	lockData, err := lock.Load(path)
 	if err != nil {
@@ -65,8 +94,32 @@ func buildExcluded(specData *spec.Spec) map[string]struct{} {
 	return excluded
 }
 
-func verifyOffline(specData *spec.Spec, lockData *lock.Lock, repoRoot string, excluded map[string]struct{}) error {
+// checksumOptions returns the dirhash options selected by the spec.
+func checksumOptions(specData *spec.Spec) dirhash.Options {
+	if specData.Checksum == nil {
+		return dirhash.Options{}
+	}
+	return dirhash.Options{Normalize: specData.Checksum.Normalize}
+}
+
+// lockChecksumOptions returns the dirhash options the lock's checksums were computed with.
+func lockChecksumOptions(lockData *lock.Lock) dirhash.Options {
+	return dirhash.Options{Normalize: lockData.Normalize}
+}
+
+// ensureChecksumMode rejects partial lock rewrites when the spec selects a different
+// checksum mode than the lock records; only a full sync rehashes every skill.
+func ensureChecksumMode(specData *spec.Spec, lockData *lock.Lock) error {
+	want := checksumOptions(specData).Normalize
+	if len(lockData.Skills) == 0 || lockData.Normalize == want {
+		return nil
+	}
+	return fmt.Errorf("checksum mode changed from %q to %q; run skv sync to rehash", lockData.Normalize, want)
+}
+
+func verifyOffline(specData *spec.Spec, lockData *lock.Lock, repoRoot string, tools []agentTool) error {
 	lockMap := indexLock(lockData)
+	hashOpts := lockChecksumOptions(lockData)
 	seen := make(map[string]struct{})
 	for _, skill := range specData.Skills {
 		if skill.Name == "" {
@@ -89,44 +142,40 @@ func verifyOffline(specData *spec.Spec, lockData *lock.Lock, repoRoot string, ex
 			if entry.Repo != skill.Repo || entry.Path != skill.Path || entry.Ref != skill.Ref {
 				return fmt.Errorf("offline mode requires lock entry for %q to match spec", skill.Name)
 			}
+			patches, err := resolvePatches(skill)
+			if err != nil {
+				return err
+			}
+			if !samePatches(patches, entry.Patches) {
+				return fmt.Errorf("offline mode requires patches for %q to match lock", skill.Name)
+			}
+			f, err := skillFilter(skill, activeScope.vendorPath(repoRoot, skill.Name))
+			if err != nil {
+				return err
+			}
+			if !f.Equal(entry.Filter) {
+				return fmt.Errorf("offline mode requires file filter for %q to match lock", skill.Name)
+			}
 		}
-		if err := verifySkill(entry, repoRoot); err != nil {
-			return err
-		}
-		if err := linkSkill(repoRoot, skill.Name, excluded); err != nil {
+		if err := verifySkill(entry, repoRoot, hashOpts); err != nil {
 			return err
 		}
-	}
-	return nil
-}
-
-func verifyLock(lockData *lock.Lock, repoRoot string) error {
-	seen := make(map[string]struct{})
-	for _, skill := range lockData.Skills {
-		if skill.Name == "" {
-			return fmt.Errorf("lock entry missing name")
-		}
-		if _, dup := seen[skill.Name]; dup {
-			return fmt.Errorf("duplicate lock entry %q", skill.Name)
-		}
-		seen[skill.Name] = struct{}{}
-
-		if err := verifySkill(skill, repoRoot); err != nil {
+		if err := linkSkill(repoRoot, skill, entry, tools, hashOpts); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func verifySkill(entry lock.Skill, repoRoot string) error {
-	vendorPath := filepath.Join(repoRoot, ".skv", "skills", entry.Name)
+func verifySkill(entry lock.Skill, repoRoot string, hashOpts dirhash.Options) error {
+	vendorPath := activeScope.vendorPath(repoRoot, entry.Name)
 	if err := ensureSkill(vendorPath); err != nil {
 		return err
 	}
 	if err := validateSkillDir(vendorPath); err != nil {
 		return err
 	}
-	checksum, err := hashDirWithTimeout(vendorPath)
+	checksum, err := hashDirWithTimeout(vendorPath, hashOpts)
 	if err != nil {
 		return err
 	}
@@ -148,7 +197,7 @@ func syncLocalSkill(repoRoot string, skill spec.SkillEntry, opts syncOptions, lo
 	if err != nil {
 		return lock.Skill{}, err
 	}
-	vendorPath := filepath.Join(repoRoot, ".skv", "skills", skill.Name)
+	vendorPath := activeScope.vendorPath(repoRoot, skill.Name)
 
 	if opts.acceptLocal {
 		if _, err := os.Stat(vendorPath); err != nil {
@@ -160,23 +209,35 @@ func syncLocalSkill(repoRoot string, skill spec.SkillEntry, opts syncOptions, lo
 		if err := validateSkillDir(vendorPath); err != nil {
 			return lock.Skill{}, err
 		}
-		checksum, err := hashDirWithTimeout(vendorPath)
+		checksum, files, err := hashSkillDir(vendorPath, opts.hash)
 		if err != nil {
 			return lock.Skill{}, err
 		}
 		license := detectLicense(vendorPath, repoRoot)
+		f, err := skillFilter(skill, vendorPath)
+		if err != nil {
+			return lock.Skill{}, err
+		}
 		return lock.Skill{
-			Name:     skill.Name,
-			Local:    skill.Local,
-			Checksum: checksum,
-			License:  license,
+			Name:       skill.Name,
+			Local:      skill.Local,
+			Checksum:   checksum,
+			Files:      files,
+			License:    license,
+			Metadata:   skillMetadata(vendorPath),
+			Filter:     f,
+			ForkedFrom: forkedFrom(skill, lockMap),
 		}, nil
 	}
 
 	if err := ensureSkill(srcPath); err != nil {
 		return lock.Skill{}, err
 	}
-	if err := validateSkillDir(srcPath); err != nil {
+	f, err := skillFilter(skill, srcPath)
+	if err != nil {
+		return lock.Skill{}, err
+	}
+	if err := validateSkillFiles(srcPath, f); err != nil {
 		return lock.Skill{}, err
 	}
 
@@ -184,28 +245,60 @@ func syncLocalSkill(repoRoot string, skill spec.SkillEntry, opts syncOptions, lo
 	if err != nil {
 		return lock.Skill{}, err
 	}
-	if !same {
-		if err := copyDirAtomic(srcPath, vendorPath); err != nil {
+	if same {
+		// Nothing is copied, so there is nothing to filter files out of.
+		dropped, err := filteredOut(srcPath, f)
+		if err != nil {
 			return lock.Skill{}, err
 		}
+		if dropped != "" {
+			return lock.Skill{}, fmt.Errorf("local skill %q is vendored in place; remove %s or stop filtering it", skill.Name, dropped)
+		}
+		if err := policyGate(opts.policy, skill, nil, srcPath); err != nil {
+			return lock.Skill{}, err
+		}
+		if err := auditGate(skill.Name, srcPath, opts.audit); err != nil {
+			return lock.Skill{}, err
+		}
+	} else if err := copyDirAtomicWith(srcPath, vendorPath, f, func(dir string) error {
+		if err := policyGate(opts.policy, skill, nil, dir); err != nil {
+			return err
+		}
+		return auditGate(skill.Name, dir, opts.audit)
+	}); err != nil {
+		return lock.Skill{}, err
 	}
 
 	if err := validateSkillDir(vendorPath); err != nil {
 		return lock.Skill{}, err
 	}
-	checksum, err := hashDirWithTimeout(vendorPath)
+	checksum, files, err := hashSkillDir(vendorPath, opts.hash)
 	if err != nil {
 		return lock.Skill{}, err
 	}
 	license := detectLicense(vendorPath, repoRoot)
 	return lock.Skill{
-		Name:     skill.Name,
-		Local:    skill.Local,
-		Checksum: checksum,
-		License:  license,
+		Name:       skill.Name,
+		Local:      skill.Local,
+		Checksum:   checksum,
+		Files:      files,
+		License:    license,
+		Metadata:   skillMetadata(vendorPath),
+		Filter:     f,
+		ForkedFrom: forkedFrom(skill, lockMap),
 	}, nil
 }
 
+// forkedFrom carries an ejected skill's fork metadata over from its existing
+// lock entry, as long as the skill still points at the same local path.
+func forkedFrom(skill spec.SkillEntry, lockMap map[string]lock.Skill) *lock.Fork {
+	existing, ok := lockMap[skill.Name]
+	if !ok || existing.Local != skill.Local {
+		return nil
+	}
+	return existing.ForkedFrom
+}
+
 func syncRemoteSkill(repoRoot string, skill spec.SkillEntry, opts syncOptions, lockMap map[string]lock.Skill) (lock.Skill, error) {
 	if skill.Repo == "" {
 		return lock.Skill{}, fmt.Errorf("skill %q missing repo", skill.Name)
@@ -219,7 +312,7 @@ func syncRemoteSkill(repoRoot string, skill spec.SkillEntry, opts syncOptions, l
 	}
 	skill.Path = cleanPath
 
-	vendorPath := filepath.Join(repoRoot, ".skv", "skills", skill.Name)
+	vendorPath := activeScope.vendorPath(repoRoot, skill.Name)
 	existing, hasLock := lockMap[skill.Name]
 
 	if opts.acceptLocal {
@@ -238,7 +331,7 @@ func syncRemoteSkill(repoRoot string, skill spec.SkillEntry, opts syncOptions, l
 		if err := validateSkillDir(vendorPath); err != nil {
 			return lock.Skill{}, err
 		}
-		checksum, err := hashDirWithTimeout(vendorPath)
+		checksum, files, err := hashSkillDir(vendorPath, opts.hash)
 		if err != nil {
 			return lock.Skill{}, err
 		}
@@ -247,13 +340,18 @@ func syncRemoteSkill(repoRoot string, skill spec.SkillEntry, opts syncOptions, l
 			license = existing.License
 		}
 		return lock.Skill{
-			Name:     skill.Name,
-			Repo:     skill.Repo,
-			Path:     skill.Path,
-			Ref:      skill.Ref,
-			Commit:   existing.Commit,
-			Checksum: checksum,
-			License:  license,
+			Name:             skill.Name,
+			Repo:             skill.Repo,
+			Path:             skill.Path,
+			Ref:              skill.Ref,
+			Commit:           existing.Commit,
+			Checksum:         checksum,
+			UpstreamChecksum: existing.UpstreamChecksum,
+			Patches:          existing.Patches,
+			Files:            files,
+			License:          license,
+			Metadata:         skillMetadata(vendorPath),
+			Filter:           existing.Filter,
 		}, nil
 	}
 
@@ -264,20 +362,50 @@ func syncRemoteSkill(repoRoot string, skill spec.SkillEntry, opts syncOptions, l
 		if err := validateSkillDir(vendorPath); err != nil {
 			return lock.Skill{}, err
 		}
-		checksum, err := hashDirWithTimeout(vendorPath)
+		checksum, err := hashDirWithTimeout(vendorPath, opts.lockHash)
+		if err != nil {
+			return lock.Skill{}, err
+		}
+		if checksum != existing.Checksum {
+			return lock.Skill{}, fmt.Errorf("vendored content for %q differs from lock; use --refresh or --accept-local", skill.Name)
+		}
+		patches, err := resolvePatches(skill)
 		if err != nil {
 			return lock.Skill{}, err
 		}
-		if checksum == existing.Checksum {
-			return existing, nil
+		f, err := skillFilter(skill, vendorPath)
+		if err != nil {
+			return lock.Skill{}, err
+		}
+		unsigned := existing.Signer == nil && signatureConfig(skill, opts.verify) != nil
+		if !samePatches(patches, existing.Patches) || !f.Equal(existing.Filter) || unsigned {
+			// Re-vendor the locked commit so only the patches or filter change,
+			// or to check its signature now that one is required.
+			pinned := skill
+			pinned.Ref = existing.Commit
+			entry, err := fetchAndVendorRemote(repoRoot, pinned, vendorPath, opts)
+			if err != nil {
+				return lock.Skill{}, err
+			}
+			entry.Ref = skill.Ref
+			return entry, nil
 		}
-		return lock.Skill{}, fmt.Errorf("vendored content for %q differs from lock; use --refresh or --accept-local", skill.Name)
+		// Cache the frontmatter for locks written without it.
+		existing.Metadata = skillMetadata(vendorPath)
+		if opts.hash != opts.lockHash || existing.Files == nil {
+			// Content is intact; rehash it under the checksum mode the spec now
+			// selects, and record per-file digests for locks written without them.
+			if existing.Checksum, existing.Files, err = hashSkillDir(vendorPath, opts.hash); err != nil {
+				return lock.Skill{}, err
+			}
+		}
+		return existing, nil
 	}
 
-	return fetchAndVendorRemote(repoRoot, skill, vendorPath)
+	return fetchAndVendorRemote(repoRoot, skill, vendorPath, opts)
 }
 
-func updateRemoteSkill(repoRoot string, skill spec.SkillEntry, lockMap map[string]lock.Skill, force bool) (lock.Skill, error) {
+func updateRemoteSkill(repoRoot string, skill spec.SkillEntry, lockMap map[string]lock.Skill, force bool, opts syncOptions) (lock.Skill, error) {
 	cleanPath, err := cleanSubpath(skill.Path)
 	if err != nil {
 		return lock.Skill{}, err
@@ -310,6 +438,13 @@ func updateRemoteSkill(repoRoot string, skill spec.SkillEntry, lockMap map[strin
 		}
 	}
 
+	var signer *lock.Signer
+	if config := signatureConfig(skill, opts.verify); config != nil {
+		if signer, err = verifySignature(skill.Name, cloneDir, commit, config); err != nil {
+			return lock.Skill{}, err
+		}
+	}
+
 	srcPath := cloneDir
 	if skill.Path != "" {
 		srcPath = filepath.Join(cloneDir, skill.Path)
@@ -317,36 +452,31 @@ func updateRemoteSkill(repoRoot string, skill spec.SkillEntry, lockMap map[strin
 	if err := ensureSkill(srcPath); err != nil {
 		return lock.Skill{}, err
 	}
-	if err := validateSkillDir(srcPath); err != nil {
+	sum, err := checkFetchedSum(skill, commit, srcPath, opts.hash)
+	if err != nil {
 		return lock.Skill{}, err
 	}
 
-	vendorPath := filepath.Join(repoRoot, ".skv", "skills", skill.Name)
-	if err := copyDirAtomic(srcPath, vendorPath); err != nil {
-		return lock.Skill{}, err
+	entry := lock.Skill{
+		Name:    skill.Name,
+		Repo:    skill.Repo,
+		Path:    skill.Path,
+		Ref:     skill.Ref,
+		Commit:  commit,
+		License: detectLicense(srcPath, cloneDir),
+		Signer:  signer,
 	}
-	if err := validateSkillDir(vendorPath); err != nil {
+	vendorPath := activeScope.vendorPath(repoRoot, skill.Name)
+	if err := vendorCheckout(srcPath, vendorPath, skill, opts, &entry); err != nil {
 		return lock.Skill{}, err
 	}
-
-	checksum, err := hashDirWithTimeout(vendorPath)
-	if err != nil {
+	if err := sum.record(); err != nil {
 		return lock.Skill{}, err
 	}
-
-	license := detectLicense(srcPath, cloneDir)
-	return lock.Skill{
-		Name:     skill.Name,
-		Repo:     skill.Repo,
-		Path:     skill.Path,
-		Ref:      skill.Ref,
-		Commit:   commit,
-		Checksum: checksum,
-		License:  license,
-	}, nil
+	return entry, nil
 }
 
-func fetchAndVendorRemote(repoRoot string, skill spec.SkillEntry, vendorPath string) (lock.Skill, error) {
+func fetchAndVendorRemote(repoRoot string, skill spec.SkillEntry, vendorPath string, opts syncOptions) (lock.Skill, error) {
 	cloneDir, err := cloneRepo(skill.Repo, skill.Ref, skill.Path)
 	if err != nil {
 		return lock.Skill{}, err
@@ -362,6 +492,13 @@ func fetchAndVendorRemote(repoRoot string, skill spec.SkillEntry, vendorPath str
 		return lock.Skill{}, err
 	}
 
+	var signer *lock.Signer
+	if config := signatureConfig(skill, opts.verify); config != nil {
+		if signer, err = verifySignature(skill.Name, cloneDir, commit, config); err != nil {
+			return lock.Skill{}, err
+		}
+	}
+
 	srcPath := cloneDir
 	if skill.Path != "" {
 		srcPath = filepath.Join(cloneDir, skill.Path)
@@ -369,32 +506,27 @@ func fetchAndVendorRemote(repoRoot string, skill spec.SkillEntry, vendorPath str
 	if err := ensureSkill(srcPath); err != nil {
 		return lock.Skill{}, err
 	}
-	if err := validateSkillDir(srcPath); err != nil {
+	sum, err := checkFetchedSum(skill, commit, srcPath, opts.hash)
+	if err != nil {
 		return lock.Skill{}, err
 	}
 
-	if err := copyDirAtomic(srcPath, vendorPath); err != nil {
-		return lock.Skill{}, err
+	entry := lock.Skill{
+		Name:    skill.Name,
+		Repo:    skill.Repo,
+		Path:    skill.Path,
+		Ref:     skill.Ref,
+		Commit:  commit,
+		License: detectLicense(srcPath, cloneDir),
+		Signer:  signer,
 	}
-	if err := validateSkillDir(vendorPath); err != nil {
+	if err := vendorCheckout(srcPath, vendorPath, skill, opts, &entry); err != nil {
 		return lock.Skill{}, err
 	}
-
-	checksum, err := hashDirWithTimeout(vendorPath)
-	if err != nil {
+	if err := sum.record(); err != nil {
 		return lock.Skill{}, err
 	}
-
-	license := detectLicense(srcPath, cloneDir)
-	return lock.Skill{
-		Name:     skill.Name,
-		Repo:     skill.Repo,
-		Path:     skill.Path,
-		Ref:      skill.Ref,
-		Commit:   commit,
-		Checksum: checksum,
-		License:  license,
-	}, nil
+	return entry, nil
 }
 
 func ensureRepoHasSkill(repo, ref string) error {
@@ -417,6 +549,45 @@ func ensureRepoHasSkill(repo, ref string) error {
 	return nil
 }
 
+// errInvalidSkillMD marks a skill rejected for its SKILL.md frontmatter.
+var errInvalidSkillMD = errors.New("invalid SKILL.md")
+
+// skillMetadata returns the SKILL.md name and description in dir for the lock,
+// or nil if the frontmatter cannot be parsed.
+func skillMetadata(dir string) *lock.Metadata {
+	fm, err := skillmd.Load(dir)
+	if err != nil {
+		return nil
+	}
+	return &lock.Metadata{Name: fm.Name, Description: fm.Description}
+}
+
+// checkSkillMD validates the SKILL.md frontmatter in dir. When strict, errors
+// reject the skill; otherwise they are printed as warnings like everything
+// else.
+func checkSkillMD(name, dir string, strict bool) error {
+	fm, err := skillmd.Load(dir)
+	if err != nil {
+		if strict {
+			return fmt.Errorf("%s: %w: %v", name, errInvalidSkillMD, err)
+		}
+		globalOutput.Warn("%s: invalid SKILL.md: %v", name, err)
+		return nil
+	}
+	problems := fm.Validate()
+	if errs := skillmd.Errors(problems); strict && len(errs) > 0 {
+		messages := make([]string, len(errs))
+		for i, p := range errs {
+			messages[i] = p.String()
+		}
+		return fmt.Errorf("%s: %w: %s", name, errInvalidSkillMD, strings.Join(messages, "; "))
+	}
+	for _, p := range problems {
+		globalOutput.Warn("%s: SKILL.md %s", name, p)
+	}
+	return nil
+}
+
 func ensureSkill(path string) error {
 	info, err := os.Stat(path)
 	if err != nil {
@@ -435,22 +606,15 @@ func ensureSkill(path string) error {
 }
 
 func validateSkillDir(root string) error {
+	return validateSkillFiles(root, nil)
+}
+
+// validateSkillFiles is like validateSkillDir but only checks the files f
+// keeps, so excluded files do not count against the limits.
+func validateSkillFiles(root string, f *filter.Filter) error {
 	var total int64
 	var count int
-	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() {
-			if d.Name() == ".git" {
-				return fs.SkipDir
-			}
-			return nil
-		}
-		info, err := d.Info()
-		if err != nil {
-			return err
-		}
+	return walkSkillFiles(root, f, func(path, _ string, info fs.FileInfo) error {
 		if info.Mode()&os.ModeSymlink != 0 {
 			return fmt.Errorf("symlinks are not allowed: %s", path)
 		}
@@ -479,6 +643,35 @@ func validateSkillDir(root string) error {
 	})
 }
 
+// walkSkillFiles calls visit for every non-directory entry under root that f
+// keeps, with its slash-separated path relative to root. .git is skipped.
+func walkSkillFiles(root string, f *filter.Filter, visit func(path, rel string, info fs.FileInfo) error) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if d.Name() == ".git" || (rel != "." && f.SkipDir(rel)) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !f.Keep(rel) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return visit(path, rel, info)
+	})
+}
+
 func validateCheckoutSize(root string) error {
 	var total int64
 	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
@@ -519,88 +712,134 @@ func isLFSPointer(path string) (bool, error) {
 	return strings.HasPrefix(content, "version https://git-lfs.github.com/spec/v1"), nil
 }
 
-func hashDirWithTimeout(path string) (string, error) {
+func hashDirWithTimeout(path string, opts dirhash.Options) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), hashTimeout)
+	defer cancel()
+	return dirhash.HashDirWithOptions(ctx, path, opts)
+}
+
+// hashSkillDir returns the directory checksum together with the per-file
+// digests recorded in lock entries.
+func hashSkillDir(path string, opts dirhash.Options) (string, map[string]string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), hashTimeout)
 	defer cancel()
-	return dirhash.HashDirWithContext(ctx, path)
+	files, err := dirhash.Files(ctx, path, opts)
+	if err != nil {
+		return "", nil, err
+	}
+	sums := make(map[string]string, len(files))
+	for _, file := range files {
+		sums[file.Path] = file.Sum
+	}
+	return dirhash.Sum(files), sums, nil
 }
 
+// licenseFiles are the names a skill's license file is looked up under, in
+// the skill directory first and then at the repo root.
+var licenseFiles = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "LICENCE", "COPYING"}
+
+// detectLicense identifies the license of the skill in skillDir from its own
+// license file, the repo's license file, or failing both an
+// SPDX-License-Identifier header or license field in its SKILL.md. Paths are
+// recorded relative to repoDir.
 func detectLicense(skillDir, repoDir string) *lock.License {
-	candidates := []string{"LICENSE", "LICENSE.txt", "COPYING", "NOTICE"}
-	path := findFirstFile(skillDir, candidates)
+	path := findFirstFile(skillDir, licenseFiles)
 	if path == "" {
-		path = findFirstFile(repoDir, candidates)
+		path = findFirstFile(repoDir, licenseFiles)
 	}
 	if path == "" {
-		for _, name := range candidates {
+		for _, name := range licenseFiles {
 			content, ok, err := gitShowFile(repoDir, name)
 			if err != nil {
 				return nil
 			}
 			if ok {
-				return &lock.License{
-					SPDX: detectSPDXText(content),
-					Path: dirhash.NormalizePath(name),
-				}
+				return identifyLicense(content, dirhash.NormalizePath(name))
 			}
 		}
+		return skillMDLicense(skillDir, repoDir)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
 		return nil
 	}
-	spdx := detectSPDX(path)
 	rel, err := filepath.Rel(repoDir, path)
 	if err != nil {
 		rel = path
 	}
-	return &lock.License{
-		SPDX: spdx,
-		Path: dirhash.NormalizePath(rel),
-	}
+	return identifyLicense(string(data), dirhash.NormalizePath(rel))
 }
 
-func findFirstFile(dir string, names []string) string {
-	for _, name := range names {
-		path := filepath.Join(dir, name)
-		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
-			return path
-		}
+// identifyLicense classifies the license text found at rel. An unrecognized
+// text is recorded with its path and no identifier.
+func identifyLicense(text, rel string) *lock.License {
+	match, ok := license.Identify(text)
+	if !ok {
+		return &lock.License{Path: rel}
 	}
-	return ""
+	return &lock.License{SPDX: match.ID, Path: rel, Confidence: match.Confidence}
 }
 
-func detectSPDX(path string) string {
+// skillMDLicense reads the license a SKILL.md declares, either in an
+// SPDX-License-Identifier line or as a frontmatter license that names a known
+// SPDX identifier.
+func skillMDLicense(skillDir, repoDir string) *lock.License {
+	path := filepath.Join(skillDir, skillmd.FileName)
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return ""
+		return nil
 	}
-	return detectSPDXText(string(data))
-}
-
-func detectSPDXText(text string) string {
-	if strings.Contains(text, "MIT License") {
-		return "MIT"
+	rel, err := filepath.Rel(repoDir, path)
+	if err != nil {
+		rel = path
 	}
-	if strings.Contains(text, "Apache License") {
-		return "Apache-2.0"
+	if id, ok := license.Header(string(data)); ok {
+		return &lock.License{SPDX: id, Path: dirhash.NormalizePath(rel), Confidence: 1}
 	}
-	if strings.Contains(text, "BSD 3-Clause") {
-		return "BSD-3-Clause"
+	fm, err := skillmd.Parse(data)
+	if err != nil {
+		return nil
 	}
-	return ""
+	declared, _ := fm.Fields["license"].(string)
+	for _, id := range license.IDs() {
+		if strings.EqualFold(strings.TrimSpace(declared), id) {
+			return &lock.License{SPDX: id, Path: dirhash.NormalizePath(rel), Confidence: 1}
+		}
+	}
+	return nil
 }
 
-func linkSkill(repoRoot, name string, excluded map[string]struct{}) error {
-	target := filepath.Join(repoRoot, ".skv", "skills", name)
-	links := map[string]string{
-		"claude":   filepath.Join(repoRoot, ".claude", "skills", name),
-		"codex":    filepath.Join(repoRoot, ".codex", "skills", name),
-		"opencode": filepath.Join(repoRoot, ".opencode", "skill", name),
+func findFirstFile(dir string, names []string) string {
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			return path
+		}
 	}
+	return ""
+}
 
-	for tool, linkPath := range links {
-		if _, skip := excluded[tool]; skip {
+// linkSkill installs the vendored skill into every tool it targets and removes
+// links it left in tools it no longer targets.
+func linkSkill(repoRoot string, skill spec.SkillEntry, entry lock.Skill, tools []agentTool, hashOpts dirhash.Options) error {
+	target := activeScope.vendorPath(repoRoot, entry.Name)
+	if entry.Metadata != nil && entry.Metadata.Name != "" && entry.Metadata.Name != entry.Name {
+		declared := entry.Metadata.Name
+		globalOutput.Warn("%s: SKILL.md name %q does not match the installed name; agents may list it as %q", entry.Name, declared, declared)
+	}
+	for _, tool := range tools {
+		linkPath := filepath.Join(repoRoot, tool.Dir, entry.Name)
+		if !linksTool(skill, tool) {
+			removed, err := removeManagedLink(repoRoot, entry.Name, linkPath)
+			if err != nil {
+				return err
+			}
+			if removed {
+				globalOutput.Info("Removed stale link %s", relPath(repoRoot, linkPath))
+			}
 			continue
 		}
-		if err := ensureLink(target, linkPath); err != nil {
+		if err := installLink(tool, target, linkPath, entry, hashOpts); err != nil {
 			return err
 		}
 	}
@@ -614,7 +853,17 @@ func ensureLink(target, linkPath string) error {
 				return err
 			}
 		} else if info.IsDir() {
-			return fmt.Errorf("refusing to replace directory %s", linkPath)
+			// A copy installed under another link mode is replaced; anything else is the user's.
+			marker, err := readLinkMarker(linkPath)
+			if err != nil {
+				return err
+			}
+			if marker == nil {
+				return fmt.Errorf("refusing to replace directory %s", linkPath)
+			}
+			if err := os.RemoveAll(linkPath); err != nil {
+				return err
+			}
 		} else if err := os.Remove(linkPath); err != nil {
 			return err
 		}
@@ -633,6 +882,27 @@ func ensureLink(target, linkPath string) error {
 	return os.Symlink(rel, linkPath)
 }
 
+// ensureGitAttribute appends line to the .gitattributes file at path unless an
+// identical line is already present. It reports whether the file changed.
+func ensureGitAttribute(path, line string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	want := strings.Fields(line)
+	for _, existing := range strings.Split(string(data), "\n") {
+		if slices.Equal(strings.Fields(existing), want) {
+			return false, nil
+		}
+	}
+	content := string(data)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += line + "\n"
+	return true, os.WriteFile(path, []byte(content), 0o644)
+}
+
 func gitCheckout(dir, ref string) error {
 	return runGitCommand(dir, "checkout", ref)
 }
@@ -671,6 +941,11 @@ func runGitCommand(dir string, args ...string) error {
 }
 
 func runGitCommandOutput(dir string, args ...string) ([]byte, error) {
+	return runGitCommandEnv(dir, nil, args...)
+}
+
+// runGitCommandEnv is like runGitCommandOutput with extra environment variables.
+func runGitCommandEnv(dir string, env []string, args ...string) ([]byte, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
 	defer cancel()
 
@@ -679,7 +954,7 @@ func runGitCommandOutput(dir string, args ...string) ([]byte, error) {
 	if dir != "" {
 		cmd.Dir = dir
 	}
-	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Env = append(append(os.Environ(), "GIT_TERMINAL_PROMPT=0"), env...)
 	out, err := cmd.CombinedOutput()
 	if ctx.Err() == context.DeadlineExceeded {
 		return nil, fmt.Errorf("git %s timed out", strings.Join(args, " "))
@@ -797,8 +1072,9 @@ func splitColon(value string) int {
 	if idx == -1 {
 		return -1
 	}
-	if strings.Contains(value[:idx], "://") {
-		return idx
+	// The colon of a URL scheme does not introduce a path.
+	if strings.HasPrefix(value[idx:], "://") {
+		return -1
 	}
 	return idx
 }
@@ -864,6 +1140,12 @@ func samePath(a, b string) (bool, error) {
 }
 
 func copyDirAtomic(src, dst string) error {
+	return copyDirAtomicWith(src, dst, nil, nil)
+}
+
+// copyDirAtomicWith is like copyDirAtomic but copies only the files f keeps
+// and lets prepare modify the copy before it replaces dst.
+func copyDirAtomicWith(src, dst string, f *filter.Filter, prepare func(dir string) error) error {
 	parent := filepath.Dir(dst)
 	tmp, err := os.MkdirTemp(parent, ".skv-tmp-")
 	if err != nil {
@@ -876,9 +1158,14 @@ func copyDirAtomic(src, dst string) error {
 		}
 	}()
 
-	if err := fsutil.CopyDir(src, tmp); err != nil {
+	if err := fsutil.CopyDir(src, tmp, f); err != nil {
 		return err
 	}
+	if prepare != nil {
+		if err := prepare(tmp); err != nil {
+			return err
+		}
+	}
 	if err := os.RemoveAll(dst); err != nil {
 		return err
 	}
@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestParseRepoArg(t *testing.T) {
+	cases := []struct {
+		arg, repo, ref, path string
+	}{
+		{"https://github.com/acme/skill-foo", "https://github.com/acme/skill-foo", "", ""},
+		{"https://github.com/acme/pack:skills/foo", "https://github.com/acme/pack", "", "skills/foo"},
+		{"https://github.com/acme/pack#v1.2.3:skills/foo", "https://github.com/acme/pack", "v1.2.3", "skills/foo"},
+		{"https://github.com/acme/pack#main", "https://github.com/acme/pack", "main", ""},
+		{"file:///tmp/repo", "file:///tmp/repo", "", ""},
+		{"../repo:skills/foo", "../repo", "", "skills/foo"},
+	}
+	for _, tc := range cases {
+		repo, ref, path := parseRepoArg(tc.arg)
+		if repo != tc.repo || ref != tc.ref || path != tc.path {
+			t.Errorf("parseRepoArg(%q) = %q, %q, %q; want %q, %q, %q", tc.arg, repo, ref, path, tc.repo, tc.ref, tc.path)
+		}
+	}
+}
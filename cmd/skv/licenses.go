@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/skill-vendor/skv/internal/lock"
+)
+
+type licensesOptions struct {
+	json bool
+}
+
+type licenseReport struct {
+	Name       string  `json:"name"`
+	License    string  `json:"license"`
+	Confidence float64 `json:"confidence,omitempty"`
+	Path       string  `json:"path,omitempty"`
+}
+
+// runLicenses reports the license recorded in the lock for each skill. A
+// license file whose text was not recognized is reported as "unknown", and a
+// skill without a license file as "none".
+func runLicenses(opts licensesOptions) error {
+	lockData, err := lock.Load(activeScope.lockPath())
+	if err != nil {
+		return err
+	}
+
+	reports := make([]licenseReport, 0, len(lockData.Skills))
+	for _, skill := range lockData.Skills {
+		report := licenseReport{Name: skill.Name, License: "none"}
+		if skill.License != nil {
+			report.License = "unknown"
+			if skill.License.SPDX != "" {
+				report.License = skill.License.SPDX
+			}
+			report.Confidence = skill.License.Confidence
+			report.Path = skill.License.Path
+		}
+		reports = append(reports, report)
+	}
+
+	if opts.json {
+		data, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	if len(reports) == 0 {
+		globalOutput.Info("No skills installed")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tLICENSE\tCONFIDENCE\tFILE")
+	for _, report := range reports {
+		confidence, file := "-", "-"
+		if report.Confidence > 0 {
+			confidence = fmt.Sprintf("%.2f", report.Confidence)
+		}
+		if report.Path != "" {
+			file = report.Path
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", report.Name, report.License, confidence, file)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/skill-vendor/skv/internal/dirhash"
+	"github.com/skill-vendor/skv/internal/filter"
+	"github.com/skill-vendor/skv/internal/skillmd"
+	"github.com/skill-vendor/skv/internal/spec"
+)
+
+type lintOptions struct {
+	json bool
+}
+
+// Lint rules. Each can be turned off with lint: { disable: [...] } in skv.cue.
+const (
+	ruleFrontmatter         = "frontmatter"
+	ruleMissingDescription  = "missing-description"
+	ruleNameMismatch        = "name-mismatch"
+	ruleTokenBudget         = "token-budget"
+	ruleBrokenLink          = "broken-link"
+	ruleMissingScript       = "missing-script"
+	ruleScriptNotExecutable = "script-not-executable"
+	ruleNonUTF8             = "non-utf8"
+)
+
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// defaultTokenBudget is the estimated token count SKILL.md should stay under;
+// agents load the whole file into context when the skill triggers.
+const defaultTokenBudget = 5000
+
+type lintFinding struct {
+	Skill    string `json:"skill"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Path     string `json:"path"` // relative to the skill directory
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (f lintFinding) String() string {
+	location := f.Path
+	if f.Line > 0 {
+		location = fmt.Sprintf("%s:%d", f.Path, f.Line)
+	}
+	return fmt.Sprintf("%s: %s: %s (%s)", f.Skill, location, f.Message, f.Rule)
+}
+
+// lintSkill is the input to the rules: the skill as installed and the
+// directory its content lives in.
+type lintSkill struct {
+	name   string
+	dir    string
+	filter *filter.Filter
+}
+
+type linter struct {
+	disabled    map[string]bool
+	tokenBudget int
+	findings    []lintFinding
+}
+
+func (l *linter) add(skill, rule, severity, rel string, line int, format string, args ...any) {
+	if l.disabled[rule] {
+		return
+	}
+	l.findings = append(l.findings, lintFinding{
+		Skill:    skill,
+		Rule:     rule,
+		Severity: severity,
+		Path:     rel,
+		Line:     line,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// runLint runs the lint rules over the named skills, or every skill in the
+// spec. Remote skills are linted as vendored; local skills at their source.
+func runLint(names []string, opts lintOptions) error {
+	repoRoot, err := activeScope.root()
+	if err != nil {
+		return err
+	}
+	specData, err := loadSpec()
+	if err != nil {
+		return err
+	}
+
+	targets := specData.Skills
+	if len(names) > 0 {
+		targets = nil
+		for _, name := range names {
+			skill, ok := findSkill(specData, name)
+			if !ok {
+				return fmt.Errorf("skill %q not found in spec", name)
+			}
+			targets = append(targets, skill)
+		}
+	}
+
+	l := newLinter(specData.Lint)
+	for _, skill := range targets {
+		dir := activeScope.vendorPath(repoRoot, skill.Name)
+		if skill.Local != "" {
+			if dir, err = resolveLocalPath(repoRoot, skill.Local); err != nil {
+				return err
+			}
+		}
+		if err := ensureSkill(dir); err != nil {
+			return fmt.Errorf("%s: %w; run skv sync", skill.Name, err)
+		}
+		f, err := skillFilter(skill, dir)
+		if err != nil {
+			return err
+		}
+		if err := l.lint(lintSkill{name: skill.Name, dir: dir, filter: f}); err != nil {
+			return fmt.Errorf("%s: %w", skill.Name, err)
+		}
+	}
+
+	errorCount := 0
+	for _, finding := range l.findings {
+		if finding.Severity == severityError {
+			errorCount++
+		}
+	}
+	if opts.json {
+		findings := l.findings
+		if findings == nil {
+			findings = []lintFinding{}
+		}
+		data, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else {
+		for _, finding := range l.findings {
+			if finding.Severity == severityError {
+				globalOutput.Error("%s", finding)
+			} else {
+				globalOutput.Warn("%s", finding)
+			}
+		}
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("lint found %d error(s) and %d warning(s)", errorCount, len(l.findings)-errorCount)
+	}
+	if !opts.json {
+		globalOutput.Success("Linted %d skill(s)", len(targets))
+	}
+	return nil
+}
+
+func newLinter(config *spec.Lint) *linter {
+	l := &linter{disabled: make(map[string]bool), tokenBudget: defaultTokenBudget}
+	if config != nil {
+		for _, rule := range config.Disable {
+			l.disabled[rule] = true
+		}
+		if config.TokenBudget > 0 {
+			l.tokenBudget = config.TokenBudget
+		}
+	}
+	return l
+}
+
+// lint runs every rule over one skill.
+func (l *linter) lint(skill lintSkill) error {
+	data, err := os.ReadFile(filepath.Join(skill.dir, skillmd.FileName))
+	if err != nil {
+		return err
+	}
+	l.lintFrontmatter(skill, data)
+	if tokens := estimateTokens(data); tokens > l.tokenBudget {
+		l.add(skill.name, ruleTokenBudget, severityWarning, skillmd.FileName, 0,
+			"about %d tokens, over the budget of %d; move detail into separate files", tokens, l.tokenBudget)
+	}
+	l.lintScripts(skill, data)
+
+	return walkSkillFiles(skill.dir, skill.filter, func(path, rel string, info fs.FileInfo) error {
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if dirhash.IsBinary(content) {
+			return nil
+		}
+		if !utf8.Valid(content) {
+			l.add(skill.name, ruleNonUTF8, severityWarning, rel, 0, "not valid UTF-8")
+			return nil
+		}
+		if strings.EqualFold(filepath.Ext(rel), ".md") {
+			l.lintLinks(skill, rel, content)
+		}
+		return nil
+	})
+}
+
+func (l *linter) lintFrontmatter(skill lintSkill, data []byte) {
+	fm, err := skillmd.Parse(data)
+	if err != nil {
+		l.add(skill.name, ruleFrontmatter, severityError, skillmd.FileName, 1, "%v", err)
+		return
+	}
+	for _, problem := range fm.Validate() {
+		rule, severity := ruleFrontmatter, severityError
+		if problem.Field == "description" && strings.TrimSpace(fm.Description) == "" {
+			rule = ruleMissingDescription
+		}
+		if problem.Warning {
+			severity = severityWarning
+		}
+		l.add(skill.name, rule, severity, skillmd.FileName, 0, "%s", problem)
+	}
+	if fm.Name != "" && fm.Name != skill.name {
+		l.add(skill.name, ruleNameMismatch, severityWarning, skillmd.FileName, 0,
+			"frontmatter name %q does not match the installed name; agents may list it as %q", fm.Name, fm.Name)
+	}
+}
+
+// estimateTokens approximates the token count of text at four bytes a token.
+func estimateTokens(data []byte) int {
+	return (len(data) + 3) / 4
+}
+
+// markdownLink matches inline links and images: [text](target "title").
+var markdownLink = regexp.MustCompile(`\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+
+// lintLinks reports relative links in the markdown file rel that do not
+// resolve to a file or directory inside the skill.
+func (l *linter) lintLinks(skill lintSkill, rel string, content []byte) {
+	forEachProseLine(content, func(n int, line string) {
+		for _, match := range markdownLink.FindAllStringSubmatch(line, -1) {
+			target := match[1]
+			if isExternalLink(target) {
+				continue
+			}
+			target, _, _ = strings.Cut(target, "#")
+			target, _, _ = strings.Cut(target, "?")
+			if target == "" {
+				continue
+			}
+			if decoded, err := url.PathUnescape(target); err == nil {
+				target = decoded
+			}
+			resolved := path.Join(path.Dir(rel), target)
+			if resolved == ".." || strings.HasPrefix(resolved, "../") {
+				l.add(skill.name, ruleBrokenLink, severityError, rel, n, "link %q points outside the skill", match[1])
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(skill.dir, filepath.FromSlash(resolved))); err != nil {
+				l.add(skill.name, ruleBrokenLink, severityError, rel, n, "link %q does not resolve to a file in the skill", match[1])
+			}
+		}
+	})
+}
+
+// isExternalLink reports whether a link target is a URL, an anchor within the
+// same file, or an absolute path, none of which name a file in the skill.
+func isExternalLink(target string) bool {
+	if strings.HasPrefix(target, "#") || strings.HasPrefix(target, "/") {
+		return true
+	}
+	if u, err := url.Parse(target); err == nil && u.Scheme != "" {
+		return true
+	}
+	return false
+}
+
+// forEachProseLine calls fn with each line of markdown outside fenced code
+// blocks, numbered from 1.
+func forEachProseLine(content []byte, fn func(n int, line string)) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxFileBytes)
+	inFence := false
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if !inFence {
+			fn(n, line)
+		}
+	}
+}
+
+// scriptExtensions mark a ./-relative path in SKILL.md as a script reference.
+var scriptExtensions = []string{".sh", ".bash", ".py", ".js", ".mjs", ".cjs", ".ts", ".rb", ".pl", ".ps1"}
+
+// lintScripts checks the scripts SKILL.md tells the agent to run: every path
+// under scripts/, and every ./-relative path with a script extension, must
+// exist in the skill, and scripts with a #! line must be executable.
+func (l *linter) lintScripts(skill lintSkill, data []byte) {
+	seen := make(map[string]bool)
+	for n, line := range strings.Split(string(data), "\n") {
+		for _, ref := range scriptRefs(line) {
+			if seen[ref] {
+				continue
+			}
+			seen[ref] = true
+			info, err := os.Stat(filepath.Join(skill.dir, filepath.FromSlash(ref)))
+			if err != nil {
+				l.add(skill.name, ruleMissingScript, severityError, skillmd.FileName, n+1, "referenced script %s is missing", ref)
+				continue
+			}
+			if !info.Mode().IsRegular() || info.Mode().Perm()&0o111 != 0 {
+				continue
+			}
+			if hasShebang(filepath.Join(skill.dir, filepath.FromSlash(ref))) {
+				l.add(skill.name, ruleScriptNotExecutable, severityWarning, skillmd.FileName, n+1,
+					"referenced script %s has a #! line but is not executable", ref)
+			}
+		}
+	}
+}
+
+// scriptRefs returns the skill-relative script paths mentioned in line.
+func scriptRefs(line string) []string {
+	words := strings.FieldsFunc(line, func(r rune) bool {
+		return r == ' ' || r == '\t' || strings.ContainsRune("`'\"()[]<>,;=", r)
+	})
+	var refs []string
+	for _, word := range words {
+		if strings.Contains(word, "://") || strings.ContainsAny(word, "*?{}$") {
+			continue
+		}
+		word = strings.TrimRight(word, ".:!")
+		var ref string
+		switch {
+		case strings.HasPrefix(word, "scripts/"):
+			ref = word
+		case strings.HasPrefix(word, "./"):
+			ref = strings.TrimPrefix(word, "./")
+			if !strings.HasPrefix(ref, "scripts/") && !slices.Contains(scriptExtensions, path.Ext(ref)) {
+				continue
+			}
+		default:
+			continue
+		}
+		ref = path.Clean(ref)
+		if ref == "scripts" || strings.HasPrefix(ref, "../") || strings.HasSuffix(word, "/") {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+func hasShebang(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	buf := make([]byte, 2)
+	n, _ := file.Read(buf)
+	return n == 2 && string(buf) == "#!"
+}
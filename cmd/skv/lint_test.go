@@ -0,0 +1,42 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestScriptRefs(t *testing.T) {
+	cases := []struct {
+		line string
+		want []string
+	}{
+		{"Run `scripts/check.sh` before committing.", []string{"scripts/check.sh"}},
+		{"python scripts/report.py --out x", []string{"scripts/report.py"}},
+		{"Use ./tools/fmt.sh or ./notes.txt", []string{"tools/fmt.sh"}},
+		{"See (./scripts/a.sh), then ./scripts/b.sh.", []string{"scripts/a.sh", "scripts/b.sh"}},
+		{"Put helpers in scripts/ and call them.", nil},
+		{"https://example.com/scripts/install.sh", nil},
+		{"scripts/*.sh and $DIR/scripts/x.sh", nil},
+		{"../scripts/outside.sh", nil},
+	}
+	for _, tc := range cases {
+		if got := scriptRefs(tc.line); !slices.Equal(got, tc.want) {
+			t.Errorf("scriptRefs(%q) = %q, want %q", tc.line, got, tc.want)
+		}
+	}
+}
+
+func TestIsExternalLink(t *testing.T) {
+	for target, want := range map[string]bool{
+		"https://example.com":  true,
+		"mailto:a@example.com": true,
+		"#usage":               true,
+		"/etc/passwd":          true,
+		"docs/usage.md":        false,
+		"../README.md":         false,
+	} {
+		if got := isExternalLink(target); got != want {
+			t.Errorf("isExternalLink(%q) = %v, want %v", target, got, want)
+		}
+	}
+}
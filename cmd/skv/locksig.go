@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/skill-vendor/skv/internal/lock"
+	"github.com/skill-vendor/skv/internal/spec"
+)
+
+// lockSignatureNamespace is the ssh-keygen -Y namespace of lock signatures,
+// so a signature made for git or another tool cannot be passed off as one.
+const lockSignatureNamespace = "skv-lock"
+
+// lockSignersEnv names an SSH allowed-signers file, set by CI, that is used
+// instead of verify.lockKeys when --allowed-signers is not given.
+const lockSignersEnv = "SKV_LOCK_SIGNERS"
+
+type lockSignOptions struct {
+	key string
+}
+
+type lockSignatureOptions struct {
+	allowedSigners string
+	// external rejects verify.lockKeys as the source of allowed keys: a
+	// change to the lock can edit skv.cue too, so only a file from outside
+	// the repository can be trusted to enforce the signature.
+	external bool
+}
+
+// lockSignaturePath is the detached signature of the active lock file.
+func lockSignaturePath() string {
+	return activeScope.lockPath() + ".sig"
+}
+
+func runLockSign(opts lockSignOptions) error {
+	lockData, err := lock.Load(activeScope.lockPath())
+	if err != nil {
+		return err
+	}
+	data, err := lock.Canonical(lockData)
+	if err != nil {
+		return err
+	}
+
+	key := opts.key
+	if key == "" {
+		if out, err := runGitCommandOutput("", "config", "user.signingkey"); err == nil {
+			key = strings.TrimSpace(string(out))
+		}
+	}
+	if key == "" {
+		return usageErrorf("lock sign needs --key or git config user.signingkey")
+	}
+	keyFile, cleanup, err := signingKeyFile(key)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	signature, err := runSSHKeygen(data, "-Y", "sign", "-f", keyFile, "-n", lockSignatureNamespace)
+	if err != nil {
+		return err
+	}
+	sigPath := lockSignaturePath()
+	if err := os.WriteFile(sigPath, signature, 0o644); err != nil {
+		return err
+	}
+
+	specData, err := loadSpec()
+	if err == nil && specData.Verify != nil && len(specData.Verify.LockKeys) > 0 {
+		if _, err := checkLockSignature(specData, lockSignatureOptions{}); err != nil {
+			globalOutput.Warn("%v", err)
+		}
+	}
+	globalOutput.Success("Signed %s (%s)", filepath.Base(activeScope.lockPath()), filepath.Base(sigPath))
+	return nil
+}
+
+func runLockVerifySignature(opts lockSignatureOptions) error {
+	specData, err := loadSpec()
+	if err != nil {
+		return err
+	}
+	signer, err := checkLockSignature(specData, opts)
+	if err != nil {
+		return err
+	}
+	globalOutput.Success("%s is signed by %s", filepath.Base(activeScope.lockPath()), signer)
+	return nil
+}
+
+// checkLockSignature checks the lock's detached signature against the
+// allowed signers file in opts or $SKV_LOCK_SIGNERS, or else the spec's
+// verify.lockKeys, and describes the key that made it.
+func checkLockSignature(specData *spec.Spec, opts lockSignatureOptions) (string, error) {
+	name := filepath.Base(activeScope.lockPath())
+	sigPath := lockSignaturePath()
+	if _, err := os.Stat(sigPath); errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%s is not signed; run skv lock sign", name)
+	}
+
+	var keys []string
+	source := "verify.lockKeys"
+	file := opts.allowedSigners
+	if file != "" {
+		source = "--allowed-signers"
+	} else if file = os.Getenv(lockSignersEnv); file != "" {
+		source = "$" + lockSignersEnv
+	}
+	switch {
+	case file != "":
+		path, err := filepath.Abs(file)
+		if err != nil {
+			return "", err
+		}
+		keys = []string{path}
+	case opts.external:
+		return "", fmt.Errorf("no allowed signers to check %s against; pass --allowed-signers or set $%s to a file "+
+			"from outside the change, since verify.lockKeys can be edited along with the lock", filepath.Base(sigPath), lockSignersEnv)
+	case specData.Verify != nil:
+		keys = specData.Verify.LockKeys
+	}
+	if len(keys) == 0 {
+		return "", fmt.Errorf("no keys to check %s against; set verify.lockKeys in skv.cue or pass --allowed-signers", filepath.Base(sigPath))
+	}
+	signers, gpgKeys, err := allowedSigners(keys, lockSignatureNamespace)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", source, err)
+	}
+	if len(gpgKeys) > 0 {
+		return "", fmt.Errorf("%s: %s: lock signatures use SSH keys", source, gpgKeys[0])
+	}
+
+	dir, err := os.MkdirTemp("", "skv-keys-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(dir)
+	signersPath := filepath.Join(dir, "allowed_signers")
+	if err := os.WriteFile(signersPath, []byte(signers), 0o600); err != nil {
+		return "", err
+	}
+
+	lockData, err := lock.Load(activeScope.lockPath())
+	if err != nil {
+		return "", err
+	}
+	data, err := lock.Canonical(lockData)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := runSSHKeygen(nil, "-Y", "find-principals", "-s", sigPath, "-f", signersPath)
+	if err != nil {
+		return "", fmt.Errorf("%s is signed by a key that is not allowed (%v)", name, err)
+	}
+	var detail error
+	for _, principal := range strings.Fields(string(out)) {
+		out, err := runSSHKeygen(data, "-Y", "verify", "-f", signersPath, "-I", principal, "-n", lockSignatureNamespace, "-s", sigPath)
+		if err != nil {
+			detail = err
+			continue
+		}
+		signer := "an allowed key"
+		if match := sshSignerPattern.FindStringSubmatch(string(out)); match != nil {
+			signer = match[1]
+		}
+		if principal != "*" {
+			signer = principal + " (" + signer + ")"
+		}
+		return signer, nil
+	}
+	return "", fmt.Errorf("%s does not match %s; was it changed after signing? (%v)", filepath.Base(sigPath), name, detail)
+}
+
+// signingKeyFile returns a file ssh-keygen can sign with for key: a private
+// key path, a public key path whose private half is in ssh-agent, or, as git
+// accepts for user.signingkey, an inline public key optionally prefixed with
+// "key::".
+func signingKeyFile(key string) (string, func(), error) {
+	inline := strings.TrimPrefix(key, "key::")
+	if !isSSHPublicKey(inline) {
+		if strings.HasPrefix(key, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", nil, err
+			}
+			key = filepath.Join(home, key[2:])
+		}
+		return key, func() {}, nil
+	}
+	f, err := os.CreateTemp("", "skv-signing-*.pub")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { _ = os.Remove(f.Name()) }
+	if _, err := f.WriteString(inline + "\n"); err != nil {
+		f.Close()
+		cleanup()
+		return "", nil, err
+	}
+	if err := f.Close(); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return f.Name(), cleanup, nil
+}
+
+// runSSHKeygen runs ssh-keygen with stdin as its input and returns its
+// standard output, or an error carrying the last line it printed.
+func runSSHKeygen(stdin []byte, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "ssh-keygen", args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("ssh-keygen %s timed out", args[1])
+		}
+		detail := lastLine(stdout.String() + stderr.String())
+		if detail == "" {
+			detail = err.Error()
+		}
+		return nil, errors.New(detail)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,397 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/skill-vendor/skv/internal/lock"
+	"github.com/skill-vendor/skv/internal/skillmd"
+	"github.com/skill-vendor/skv/internal/spec"
+)
+
+// defaultNoticesPath is where skv notices writes when skv.cue sets no output.
+const defaultNoticesPath = "THIRD_PARTY_NOTICES.md"
+
+// noticesMarker starts the first line of every generated notices file. The
+// rest of the line records the digest of the lock entries it was built from,
+// which is how verify tells a stale file without fetching anything.
+const noticesMarker = "<!-- Generated by skv notices from skv.lock; do not edit. digest: "
+
+// noticeFiles are the attribution files copied alongside the license, looked
+// up in the skill directory and at the repo root.
+var noticeFiles = []string{"NOTICE", "NOTICE.md", "NOTICE.txt"}
+
+type noticesOptions struct {
+	output string
+}
+
+// attribution is a third-party skill that needs its license and notices
+// reproduced: a vendored remote skill, or the upstream of an ejected one.
+type attribution struct {
+	Name     string
+	Repo     string
+	Path     string
+	Commit   string
+	Checksum string
+	License  *lock.License
+	// vendorDir holds the vendored content at Commit, if there is a copy that
+	// has not been edited.
+	vendorDir string
+}
+
+type noticeText struct {
+	Path string // repo-relative
+	Text string
+}
+
+// runNotices writes the license and NOTICE texts of every third-party skill
+// in skv.lock to opts.output, the notices output in skv.cue, or
+// THIRD_PARTY_NOTICES.md. Texts are read from the vendored copy when it has
+// them and from the repo at the locked commit otherwise.
+func runNotices(opts noticesOptions) error {
+	repoRoot, err := activeScope.root()
+	if err != nil {
+		return err
+	}
+	specData, err := loadSpec()
+	if err != nil {
+		return err
+	}
+	lockData, err := lock.Load(activeScope.lockPath())
+	if err != nil {
+		return err
+	}
+
+	output := opts.output
+	if output == "" {
+		output = noticesOutput(specData)
+	}
+	attributions := collectAttributions(repoRoot, lockData)
+
+	clones := map[string]string{}
+	defer func() {
+		for _, dir := range clones {
+			_ = os.RemoveAll(dir)
+		}
+	}()
+	texts := make([][]noticeText, len(attributions))
+	for i, a := range attributions {
+		if texts[i], err = readNotices(a, clones); err != nil {
+			return fmt.Errorf("%s: %w", a.Name, err)
+		}
+	}
+
+	target := filepath.Join(repoRoot, filepath.FromSlash(output))
+	if !noticesDirMode(output, target) {
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		var b strings.Builder
+		b.WriteString(noticesHeader(attributions...))
+		b.WriteString("# Third-Party Notices\n\n")
+		b.WriteString("The skills below are vendored from third-party repositories with skv. Their license and notice texts follow.\n")
+		if len(attributions) == 0 {
+			b.WriteString("\nNo third-party skills are vendored.\n")
+		}
+		for i, a := range attributions {
+			b.WriteString("\n")
+			writeAttribution(&b, "##", a, texts[i])
+		}
+		if err := os.WriteFile(target, []byte(b.String()), 0o644); err != nil {
+			return err
+		}
+	} else {
+		if err := os.MkdirAll(target, 0o755); err != nil {
+			return err
+		}
+		keep := map[string]bool{}
+		for i, a := range attributions {
+			var b strings.Builder
+			b.WriteString(noticesHeader(a))
+			writeAttribution(&b, "#", a, texts[i])
+			name := a.Name + ".md"
+			keep[name] = true
+			if err := os.WriteFile(filepath.Join(target, name), []byte(b.String()), 0o644); err != nil {
+				return err
+			}
+		}
+		stale, err := generatedNotices(target)
+		if err != nil {
+			return err
+		}
+		for name := range stale {
+			if !keep[name] {
+				if err := os.Remove(filepath.Join(target, name)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	globalOutput.Success("Wrote notices for %d third-party skill(s) to %s", len(attributions), output)
+	return nil
+}
+
+// noticesOutput returns the notices output configured in specData.
+func noticesOutput(specData *spec.Spec) string {
+	if specData.Notices != nil && specData.Notices.Output != "" {
+		return specData.Notices.Output
+	}
+	return defaultNoticesPath
+}
+
+// noticesDirMode reports whether output names a directory of per-skill files:
+// it ends in a slash or already is a directory.
+func noticesDirMode(output, target string) bool {
+	if strings.HasSuffix(output, "/") {
+		return true
+	}
+	info, err := os.Stat(target)
+	return err == nil && info.IsDir()
+}
+
+// collectAttributions returns the third-party skills in lockData, by name.
+func collectAttributions(repoRoot string, lockData *lock.Lock) []attribution {
+	var attributions []attribution
+	for _, entry := range lockData.Skills {
+		switch {
+		case entry.Repo != "":
+			vendorDir := activeScope.vendorPath(repoRoot, entry.Name)
+			if len(entry.Patches) > 0 {
+				vendorDir = ""
+			}
+			attributions = append(attributions, attribution{
+				Name: entry.Name, Repo: entry.Repo, Path: entry.Path, Commit: entry.Commit,
+				Checksum: upstreamChecksum(entry), License: entry.License, vendorDir: vendorDir,
+			})
+		case entry.ForkedFrom != nil:
+			fork := entry.ForkedFrom
+			attributions = append(attributions, attribution{
+				Name: entry.Name, Repo: fork.Repo, Path: fork.Path, Commit: fork.Commit,
+				Checksum: fork.Checksum, License: fork.License,
+			})
+		}
+	}
+	sort.Slice(attributions, func(i, j int) bool { return attributions[i].Name < attributions[j].Name })
+	return attributions
+}
+
+// noticesDigest identifies the lock entries a notices file was built from.
+func noticesDigest(attributions ...attribution) string {
+	h := sha256.New()
+	for _, a := range attributions {
+		licensePath := ""
+		if a.License != nil {
+			licensePath = a.License.Path
+		}
+		fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%s\x00%s\n", a.Name, a.Repo, a.Path, a.Commit, a.Checksum, licensePath)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func noticesHeader(attributions ...attribution) string {
+	return noticesMarker + noticesDigest(attributions...) + " -->\n"
+}
+
+// readNotices returns the license text of a and every NOTICE file in its
+// skill directory or at its repo root. clones caches repos cloned at a
+// commit, keyed by repo and commit.
+func readNotices(a attribution, clones map[string]string) ([]noticeText, error) {
+	var candidates []string
+	if a.License != nil && a.License.Path != "" && path.Base(a.License.Path) != skillmd.FileName {
+		candidates = append(candidates, a.License.Path)
+	}
+	for _, dir := range []string{a.Path, ""} {
+		for _, name := range noticeFiles {
+			candidates = append(candidates, path.Join(dir, name))
+		}
+		if a.Path == "" {
+			break
+		}
+	}
+
+	var texts []noticeText
+	seen := map[string]bool{}
+	for _, rel := range candidates {
+		if seen[rel] {
+			continue
+		}
+		seen[rel] = true
+		text, ok, err := readAtCommit(a, rel, clones)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			texts = append(texts, noticeText{Path: rel, Text: text})
+		}
+	}
+	return texts, nil
+}
+
+// readAtCommit reads the repo-relative file rel as of a.Commit, from the
+// vendored copy if it is there and from a clone of the repo otherwise.
+func readAtCommit(a attribution, rel string, clones map[string]string) (string, bool, error) {
+	if a.vendorDir != "" {
+		inSkill := rel
+		if a.Path != "" {
+			inSkill = strings.TrimPrefix(rel, a.Path+"/")
+		}
+		if inSkill != rel || a.Path == "" {
+			data, err := os.ReadFile(filepath.Join(a.vendorDir, filepath.FromSlash(inSkill)))
+			if err == nil {
+				return string(data), true, nil
+			}
+			if !errors.Is(err, os.ErrNotExist) {
+				return "", false, err
+			}
+		}
+	}
+	key := a.Repo + "@" + a.Commit + ":" + a.Path
+	cloneDir, ok := clones[key]
+	if !ok {
+		var err error
+		skill := spec.SkillEntry{Name: a.Name, Repo: a.Repo, Path: a.Path}
+		if cloneDir, err = cloneLockedCommit(skill, a.Commit, a.Path); err != nil {
+			return "", false, err
+		}
+		clones[key] = cloneDir
+	}
+	return gitShowFile(cloneDir, rel)
+}
+
+// writeAttribution writes the section for a under a heading of level.
+func writeAttribution(b *strings.Builder, level string, a attribution, texts []noticeText) {
+	fmt.Fprintf(b, "%s %s\n\n", level, a.Name)
+	source := a.Repo
+	if a.Path != "" {
+		source += ":" + a.Path
+	}
+	fmt.Fprintf(b, "- Source: %s @ %s\n", source, a.Commit)
+	switch {
+	case a.License == nil:
+		b.WriteString("- License: none found\n")
+	case a.License.SPDX == "":
+		fmt.Fprintf(b, "- License: not recognized (%s)\n", a.License.Path)
+	default:
+		fmt.Fprintf(b, "- License: %s\n", a.License.SPDX)
+	}
+	for _, text := range texts {
+		fence := markdownFence(text.Text)
+		fmt.Fprintf(b, "\n%s# %s\n\n%stext\n%s\n%s\n", level, text.Path, fence, strings.TrimRight(text.Text, "\n"), fence)
+	}
+}
+
+// markdownFence returns a code fence longer than any run of backticks in text.
+func markdownFence(text string) string {
+	longest, run := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+// generatedNotices returns the names of the files in dir that skv notices
+// generated, mapped to the digest each records.
+func generatedNotices(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	digests := map[string]string{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".md") {
+			continue
+		}
+		digest, ok, err := readNoticesDigest(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			digests[entry.Name()] = digest
+		}
+	}
+	return digests, nil
+}
+
+// readNoticesDigest returns the digest recorded in the generated notices
+// file at path; it is false if the file was not generated by skv notices.
+func readNoticesDigest(path string) (string, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && line == "" {
+		return "", false, nil
+	}
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), noticesMarker)
+	if !ok {
+		return "", false, nil
+	}
+	return strings.TrimSuffix(rest, " -->"), true, nil
+}
+
+// noticesFindings reports a notices file that is missing or was generated
+// from different lock entries. It checks the output set in skv.cue, or
+// THIRD_PARTY_NOTICES.md if that exists.
+func noticesFindings(repoRoot string, specData *spec.Spec, lockData *lock.Lock) []verifyFinding {
+	configured := specData.Notices != nil && specData.Notices.Output != ""
+	output := noticesOutput(specData)
+	target := filepath.Join(repoRoot, filepath.FromSlash(output))
+	if _, err := os.Stat(target); errors.Is(err, os.ErrNotExist) {
+		if !configured {
+			return nil
+		}
+		return []verifyFinding{{Category: verifyNotices, Path: output, Message: fmt.Sprintf("%s is missing; run skv notices", output)}}
+	}
+
+	attributions := collectAttributions(repoRoot, lockData)
+	stale := func(skill, rel string) verifyFinding {
+		return verifyFinding{Category: verifyNotices, Skill: skill, Path: rel, Message: fmt.Sprintf("%s is out of date with skv.lock; run skv notices", rel)}
+	}
+	if !noticesDirMode(output, target) {
+		digest, ok, err := readNoticesDigest(target)
+		if err != nil {
+			return []verifyFinding{{Category: verifyNotices, Path: output, Message: err.Error()}}
+		}
+		if !ok || digest != noticesDigest(attributions...) {
+			return []verifyFinding{stale("", output)}
+		}
+		return nil
+	}
+
+	generated, err := generatedNotices(target)
+	if err != nil {
+		return []verifyFinding{{Category: verifyNotices, Path: output, Message: err.Error()}}
+	}
+	var findings []verifyFinding
+	for _, a := range attributions {
+		name := a.Name + ".md"
+		if generated[name] != noticesDigest(a) {
+			findings = append(findings, stale(a.Name, path.Join(output, name)))
+		}
+		delete(generated, name)
+	}
+	names := make([]string, 0, len(generated))
+	for name := range generated {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		findings = append(findings, stale(strings.TrimSuffix(name, ".md"), path.Join(output, name)))
+	}
+	return findings
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,14 +10,20 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/skill-vendor/skv/internal/dirhash"
 	"github.com/skill-vendor/skv/internal/fsutil"
 	"github.com/skill-vendor/skv/internal/lock"
 	"github.com/skill-vendor/skv/internal/spec"
 )
 
+type initOptions struct {
+	adopt bool
+}
+
 type addOptions struct {
-	name   string
-	noSync bool
+	name      string
+	namespace string
+	noSync    bool
 }
 
 type listOptions struct {
@@ -28,6 +35,30 @@ type syncOptions struct {
 	offline     bool
 	refresh     bool
 	acceptLocal bool
+
+	// hash is the checksum mode selected by the spec; lockHash is the mode
+	// the existing lock entries were written with.
+	hash     dirhash.Options
+	lockHash dirhash.Options
+
+	// audit, when it sets a block threshold, stops content with findings at
+	// or above it from being vendored; policy stops content it forbids.
+	audit  *spec.Audit
+	policy *spec.Policy
+	// verify is the spec-wide signature requirement for remote skills.
+	verify *spec.Verify
+}
+
+// reportOptions selects how verify and status render their results.
+type reportOptions struct {
+	format string
+	// requireSignature makes verify check the lock's signature.
+	requireSignature bool
+	signature        lockSignatureOptions
+}
+
+type importOptions struct {
+	detectUpstream string
 }
 
 type updateOptions struct {
@@ -36,20 +67,30 @@ type updateOptions struct {
 	force bool
 }
 
-func runInit() error {
-	if _, err := os.Stat("skv.cue"); err == nil {
-		return fmt.Errorf("skv.cue already exists")
+func runInit(opts initOptions) error {
+	if _, err := os.Stat(activeScope.specPath()); err == nil {
+		return fmt.Errorf("%s already exists", activeScope.specPath())
 	}
-	if err := fsutil.EnsureDir(filepath.Join(".skv", "skills")); err != nil {
+	repoRoot, err := activeScope.root()
+	if err != nil {
 		return err
 	}
-	if err := spec.Write("skv.cue", &spec.Spec{Skills: []spec.SkillEntry{}}); err != nil {
+	if opts.adopt {
+		return runAdopt(repoRoot)
+	}
+	if err := fsutil.EnsureDir(activeScope.vendorRoot(repoRoot)); err != nil {
+		return err
+	}
+	if err := fsutil.EnsureDir(filepath.Dir(activeScope.specPath())); err != nil {
 		return err
 	}
-	if err := lock.Write("skv.lock", &lock.Lock{Skills: []lock.Skill{}}); err != nil {
+	if err := spec.Write(activeScope.specPath(), &spec.Spec{Skills: []spec.SkillEntry{}}); err != nil {
 		return err
 	}
-	globalOutput.Success("Initialized skv in current directory")
+	if err := lock.Write(activeScope.lockPath(), &lock.Lock{Skills: []lock.Skill{}}); err != nil {
+		return err
+	}
+	globalOutput.Success("Initialized skv in %s", activeScope.describe())
 	return nil
 }
 
@@ -77,41 +118,66 @@ func runAdd(repoArg string, opts addOptions) error {
 		}
 	}
 
-	specData, err := spec.Load("skv.cue")
+	if opts.namespace != "" && !spec.ValidNamespace(opts.namespace) {
+		return usageErrorf("invalid namespace %q", opts.namespace)
+	}
+
+	specData, err := spec.Load(activeScope.specPath())
 	if err != nil {
 		return err
 	}
+	entry := spec.SkillEntry{
+		Name:      name,
+		Repo:      repo,
+		Path:      path,
+		Ref:       ref,
+		Namespace: opts.namespace,
+	}
+	qualified := specData.QualifiedName(entry)
 	for _, skill := range specData.Skills {
-		if skill.Name == name {
-			return fmt.Errorf("skill %q already exists", name)
+		if specData.QualifiedName(skill) == qualified {
+			return fmt.Errorf("skill %q already exists", qualified)
 		}
 	}
 
+	policy, err := loadPolicy(specData)
+	if err != nil {
+		return err
+	}
+	if err := reportPolicy(sourceViolations(policy, spec.SkillEntry{Name: qualified, Repo: repo, Ref: ref})); err != nil {
+		return fmt.Errorf("%w; %s was not added", err, qualified)
+	}
+
 	if path == "" {
 		if err := ensureRepoHasSkill(repo, ref); err != nil {
 			return err
 		}
 	}
 
-	entry := spec.SkillEntry{
-		Name: name,
-		Repo: repo,
-		Path: path,
-		Ref:  ref,
-	}
-
 	specData.Skills = append(specData.Skills, entry)
-	if err := spec.Write("skv.cue", specData); err != nil {
+	if err := spec.Write(activeScope.specPath(), specData); err != nil {
 		return err
 	}
-	globalOutput.Success("Added %s from %s", name, repo)
+	globalOutput.Success("Added %s from %s", qualified, repo)
 
 	if opts.noSync {
 		return nil
 	}
 
 	// Auto-sync the newly added skill
-	return runSyncSingle(entry)
+	err = runSyncSingle(entry)
+	if errors.Is(err, errInvalidSkillMD) || errors.Is(err, errPolicyViolation) {
+		// Nothing was locked; take the rejected skill back out entirely.
+		specData.Skills = specData.Skills[:len(specData.Skills)-1]
+		if werr := spec.Write(activeScope.specPath(), specData); werr != nil {
+			return werr
+		}
+		if repoRoot, rerr := activeScope.root(); rerr == nil {
+			_ = os.RemoveAll(activeScope.vendorPath(repoRoot, qualified))
+		}
+		return fmt.Errorf("%w; %s was not added", err, qualified)
+	}
+	return err
 }
 
 func runSync(opts syncOptions) error {
@@ -122,43 +188,66 @@ func runSync(opts syncOptions) error {
 		return usageErrorf("--refresh and --accept-local are mutually exclusive")
 	}
 
-	specData, err := spec.Load("skv.cue")
+	specData, err := loadSpec()
 	if err != nil {
 		return err
 	}
 
-	if err := fsutil.EnsureDir(filepath.Join(".skv", "skills")); err != nil {
+	repoRoot, err := activeScope.root()
+	if err != nil {
+		return err
+	}
+	if err := fsutil.EnsureDir(activeScope.vendorRoot(repoRoot)); err != nil {
 		return err
 	}
 
-	repoRoot, err := os.Getwd()
+	tools, err := resolveTools(specData)
 	if err != nil {
 		return err
 	}
 
-	excluded := buildExcluded(specData)
-
 	if opts.offline {
-		lockData, err := lock.Load("skv.lock")
+		lockData, err := lock.Load(activeScope.lockPath())
 		if err != nil {
 			return err
 		}
-		if err := verifyOffline(specData, lockData, repoRoot, excluded); err != nil {
+		if want := checksumOptions(specData).Normalize; lockData.Normalize != want {
+			return fmt.Errorf("offline mode requires lock checksum mode %q to match spec (%q)", lockData.Normalize, want)
+		}
+		if err := verifyOffline(specData, lockData, repoRoot, tools); err != nil {
 			return err
 		}
 		globalOutput.Success("Verified %d skill(s) in offline mode", len(specData.Skills))
 		return nil
 	}
 
-	lockData, lockMap, err := loadLockOptional("skv.lock")
+	lockData, lockMap, err := loadLockOptional(activeScope.lockPath())
 	if err != nil {
 		return err
 	}
+	policy, err := loadPolicy(specData)
+	if err != nil {
+		return err
+	}
+	var violations []policyViolation
+	for _, skill := range specData.Skills {
+		violations = append(violations, sourceViolations(policy, skill)...)
+	}
+	if err := reportPolicy(violations); err != nil {
+		return err
+	}
 
-	_ = lockData
 	seen := make(map[string]struct{})
 	var lockSkills []lock.Skill
-	passThrough := syncOptions{refresh: opts.refresh, acceptLocal: opts.acceptLocal}
+	passThrough := syncOptions{
+		refresh:     opts.refresh,
+		acceptLocal: opts.acceptLocal,
+		hash:        checksumOptions(specData),
+		lockHash:    lockChecksumOptions(lockData),
+		audit:       specData.Audit,
+		policy:      policy,
+		verify:      specData.Verify,
+	}
 
 	for _, skill := range specData.Skills {
 		if skill.Name == "" {
@@ -180,15 +269,21 @@ func runSync(opts syncOptions) error {
 		if err != nil {
 			return err
 		}
+		if err := policyGate(policy, skill, entry.License, activeScope.vendorPath(repoRoot, skill.Name)); err != nil {
+			return err
+		}
+		if err := checkSkillMD(skill.Name, activeScope.vendorPath(repoRoot, skill.Name), false); err != nil {
+			return err
+		}
 
 		lockSkills = append(lockSkills, entry)
-		if err := linkSkill(repoRoot, skill.Name, excluded); err != nil {
+		if err := linkSkill(repoRoot, skill, entry, tools, passThrough.hash); err != nil {
 			return err
 		}
 	}
 
 	sort.Slice(lockSkills, func(i, j int) bool { return lockSkills[i].Name < lockSkills[j].Name })
-	if err := lock.Write("skv.lock", &lock.Lock{Skills: lockSkills}); err != nil {
+	if err := lock.Write(activeScope.lockPath(), &lock.Lock{Normalize: passThrough.hash.Normalize, Skills: lockSkills}); err != nil {
 		return err
 	}
 	globalOutput.Success("Synced %d skill(s)", len(lockSkills))
@@ -209,25 +304,37 @@ func runUpdate(name string, opts updateOptions) error {
 		return usageErrorf("cannot combine a skill name with --all")
 	}
 
-	specData, err := spec.Load("skv.cue")
+	specData, err := loadSpec()
 	if err != nil {
 		return err
 	}
 
-	repoRoot, err := os.Getwd()
+	repoRoot, err := activeScope.root()
 	if err != nil {
 		return err
 	}
-	if err := fsutil.EnsureDir(filepath.Join(".skv", "skills")); err != nil {
+	if err := fsutil.EnsureDir(activeScope.vendorRoot(repoRoot)); err != nil {
 		return err
 	}
 
-	excluded := buildExcluded(specData)
+	tools, err := resolveTools(specData)
+	if err != nil {
+		return err
+	}
 
-	lockData, lockMap, err := loadLockRequired("skv.lock")
+	lockData, lockMap, err := loadLockRequired(activeScope.lockPath())
 	if err != nil {
 		return err
 	}
+	if err := ensureChecksumMode(specData, lockData); err != nil {
+		return err
+	}
+	hashOpts := checksumOptions(specData)
+	policy, err := loadPolicy(specData)
+	if err != nil {
+		return err
+	}
+	vendorOpts := syncOptions{hash: hashOpts, audit: specData.Audit, policy: policy, verify: specData.Verify}
 
 	var targets []spec.SkillEntry
 	if name != "" {
@@ -261,15 +368,28 @@ func runUpdate(name string, opts updateOptions) error {
 		globalOutput.Info("No skills to update")
 		return nil
 	}
+	var violations []policyViolation
+	for _, skill := range targets {
+		violations = append(violations, sourceViolations(policy, skill)...)
+	}
+	if err := reportPolicy(violations); err != nil {
+		return err
+	}
 
 	for _, skill := range targets {
 		globalOutput.Info("Updating %s...", skill.Name)
-		entry, err := updateRemoteSkill(repoRoot, skill, lockMap, opts.force)
+		entry, err := updateRemoteSkill(repoRoot, skill, lockMap, opts.force, vendorOpts)
 		if err != nil {
 			return err
 		}
+		if err := policyGate(policy, skill, entry.License, activeScope.vendorPath(repoRoot, skill.Name)); err != nil {
+			return err
+		}
+		if err := checkSkillMD(skill.Name, activeScope.vendorPath(repoRoot, skill.Name), false); err != nil {
+			return err
+		}
 		lockMap[skill.Name] = entry
-		if err := linkSkill(repoRoot, skill.Name, excluded); err != nil {
+		if err := linkSkill(repoRoot, skill, entry, tools, hashOpts); err != nil {
 			return err
 		}
 	}
@@ -287,38 +407,79 @@ func runUpdate(name string, opts updateOptions) error {
 	}
 
 	sort.Slice(lockSkills, func(i, j int) bool { return lockSkills[i].Name < lockSkills[j].Name })
+	lockData.Normalize = hashOpts.Normalize
 	lockData.Skills = lockSkills
-	if err := lock.Write("skv.lock", lockData); err != nil {
+	if err := lock.Write(activeScope.lockPath(), lockData); err != nil {
 		return err
 	}
 	globalOutput.Success("Updated %d skill(s)", len(targets))
 	return nil
 }
 
-func runVerify() error {
-	lockData, err := lock.Load("skv.lock")
+func runVerify(opts reportOptions) error {
+	if err := validateFormat(opts.format); err != nil {
+		return err
+	}
+	specData, err := loadSpec()
+	if err != nil {
+		return err
+	}
+	lockData, err := lock.Load(activeScope.lockPath())
 	if err != nil {
 		return err
 	}
 
-	repoRoot, err := os.Getwd()
+	repoRoot, err := activeScope.root()
 	if err != nil {
 		return err
 	}
 
-	if err := verifyLock(lockData, repoRoot); err != nil {
+	tools, err := resolveTools(specData)
+	if err != nil {
 		return err
 	}
+
+	policy, err := loadPolicy(specData)
+	if err != nil {
+		return err
+	}
+
+	report := buildVerifyReport(repoRoot, specData, lockData, tools, policy)
+	if opts.requireSignature {
+		if _, err := checkLockSignature(specData, opts.signature); err != nil {
+			report.Findings = append(report.Findings, verifyFinding{
+				Category: verifyLockSignature,
+				Path:     relPath(repoRoot, lockSignaturePath()),
+				Message:  err.Error(),
+			})
+			report.OK = false
+		}
+	}
+	if opts.format != formatText {
+		if err := writeReport(os.Stdout, opts.format, "verify", report); err != nil {
+			return err
+		}
+	} else {
+		for _, finding := range report.Findings {
+			globalOutput.Error("%s", finding)
+		}
+	}
+	if !report.OK {
+		return fmt.Errorf("verify found %d problem(s)", len(report.Findings))
+	}
+	if opts.format != formatText {
+		return nil
+	}
 	globalOutput.Success("Verified %d skill(s)", len(lockData.Skills))
 	return nil
 }
 
-func runImport(inputPath string) error {
+func runImport(inputPath string, opts importOptions) error {
 	if inputPath == "" {
 		return usageErrorf("import requires <agentDir>/<skill>")
 	}
 
-	repoRoot, err := os.Getwd()
+	repoRoot, err := activeScope.root()
 	if err != nil {
 		return err
 	}
@@ -336,7 +497,13 @@ func runImport(inputPath string) error {
 	}
 
 	name := filepath.Base(absPath)
-	vendorPath := filepath.Join(repoRoot, ".skv", "skills", name)
+	vendorPath := activeScope.vendorPath(repoRoot, name)
+
+	localPath := relPath(repoRoot, vendorPath)
+	if localPath == ".." || strings.HasPrefix(localPath, "../") {
+		return fmt.Errorf("cannot import: vendor directory %s is outside %s", vendorPath, repoRoot)
+	}
+	localPath = "./" + localPath
 
 	if err := fsutil.EnsureDir(filepath.Dir(vendorPath)); err != nil {
 		return err
@@ -345,6 +512,36 @@ func runImport(inputPath string) error {
 		return fmt.Errorf("vendored skill already exists: %s", vendorPath)
 	}
 
+	// Find the upstream before moving anything so a miss leaves the tree as is.
+	var upstream *upstreamResult
+	var upstreamRepo string
+	if opts.detectUpstream != "" {
+		repo, ref, subpath, err := parseUpstreamArg(opts.detectUpstream)
+		if err != nil {
+			return err
+		}
+		specData, err := spec.Load(activeScope.specPath())
+		if err != nil {
+			return err
+		}
+		globalOutput.Info("Searching %s for %s...", repo, name)
+		upstream, err = findUpstream(absPath, repo, ref, subpath, checksumOptions(specData))
+		if err != nil {
+			return err
+		}
+		if upstream == nil || !upstream.exact {
+			return fmt.Errorf("%w; nothing was imported", reportUpstreamMiss(name, repo, upstream))
+		}
+		upstreamRepo = repo
+	}
+
+	if err := ensureSkill(absPath); err != nil {
+		return err
+	}
+	if err := checkSkillMD(name, absPath, true); err != nil {
+		return err
+	}
+
 	if err := os.Rename(absPath, vendorPath); err != nil {
 		return err
 	}
@@ -356,33 +553,36 @@ func runImport(inputPath string) error {
 		return err
 	}
 
-	checksum, err := hashDirWithTimeout(vendorPath)
+	specData, err := spec.Load(activeScope.specPath())
+	if err != nil {
+		return err
+	}
+	lockData, lockMap, err := loadLockOptional(activeScope.lockPath())
+	if err != nil {
+		return err
+	}
+	if err := ensureChecksumMode(specData, lockData); err != nil {
+		return err
+	}
+	hashOpts := checksumOptions(specData)
+
+	checksum, files, err := hashSkillDir(vendorPath, hashOpts)
 	if err != nil {
 		return err
 	}
 
-	localPath := filepath.ToSlash(filepath.Join(".", ".skv", "skills", name))
 	entry := spec.SkillEntry{
 		Name:  name,
 		Local: localPath,
 	}
 
-	specData, err := spec.Load("skv.cue")
-	if err != nil {
-		return err
-	}
 	for _, skill := range specData.Skills {
-		if skill.Name == name {
+		if specData.QualifiedName(skill) == name {
 			return fmt.Errorf("skill %q already exists in spec", name)
 		}
 	}
 	specData.Skills = append(specData.Skills, entry)
-	if err := spec.Write("skv.cue", specData); err != nil {
-		return err
-	}
-
-	lockData, lockMap, err := loadLockOptional("skv.lock")
-	if err != nil {
+	if err := spec.Write(activeScope.specPath(), specData); err != nil {
 		return err
 	}
 
@@ -391,62 +591,93 @@ func runImport(inputPath string) error {
 		Name:     name,
 		Local:    localPath,
 		Checksum: checksum,
+		Files:    files,
 		License:  license,
+		Metadata: skillMetadata(vendorPath),
 	}
 
 	var lockSkills []lock.Skill
 	for _, skill := range specData.Skills {
-		if entry, ok := lockMap[skill.Name]; ok {
+		if entry, ok := lockMap[specData.QualifiedName(skill)]; ok {
 			lockSkills = append(lockSkills, entry)
 		}
 	}
 	sort.Slice(lockSkills, func(i, j int) bool { return lockSkills[i].Name < lockSkills[j].Name })
+	lockData.Normalize = hashOpts.Normalize
 	lockData.Skills = lockSkills
-	if err := lock.Write("skv.lock", lockData); err != nil {
+	if err := lock.Write(activeScope.lockPath(), lockData); err != nil {
 		return err
 	}
 
-	excluded := buildExcluded(specData)
-	if err := linkSkill(repoRoot, name, excluded); err != nil {
+	tools, err := resolveTools(specData)
+	if err != nil {
+		return err
+	}
+	if err := linkSkill(repoRoot, entry, lockMap[name], tools, hashOpts); err != nil {
 		return err
 	}
 	globalOutput.Success("Imported %s", name)
+	if upstream != nil {
+		return pinToUpstream(repoRoot, name, upstreamRepo, upstream)
+	}
 	return nil
 }
 
-// runSyncSingle syncs a single skill entry (used by add with auto-sync).
+// runSyncSingle syncs a single skill entry (used by add with auto-sync). The
+// skill is rejected, and nothing is locked, if its SKILL.md frontmatter is
+// invalid.
 func runSyncSingle(skill spec.SkillEntry) error {
-	if err := fsutil.EnsureDir(filepath.Join(".skv", "skills")); err != nil {
+	repoRoot, err := activeScope.root()
+	if err != nil {
+		return err
+	}
+	if err := fsutil.EnsureDir(activeScope.vendorRoot(repoRoot)); err != nil {
 		return err
 	}
 
-	repoRoot, err := os.Getwd()
+	specData, err := loadSpec()
 	if err != nil {
 		return err
 	}
-
-	specData, err := spec.Load("skv.cue")
+	skill.Name = specData.QualifiedName(skill)
+	tools, err := resolveTools(specData)
 	if err != nil {
 		return err
 	}
-	excluded := buildExcluded(specData)
 
-	lockData, lockMap, err := loadLockOptional("skv.lock")
+	lockData, lockMap, err := loadLockOptional(activeScope.lockPath())
+	if err != nil {
+		return err
+	}
+	if err := ensureChecksumMode(specData, lockData); err != nil {
+		return err
+	}
+	policy, err := loadPolicy(specData)
 	if err != nil {
 		return err
 	}
+	if err := reportPolicy(sourceViolations(policy, skill)); err != nil {
+		return err
+	}
+	opts := syncOptions{hash: checksumOptions(specData), lockHash: lockChecksumOptions(lockData), audit: specData.Audit, policy: policy, verify: specData.Verify}
 
 	globalOutput.Info("Fetching %s...", skill.Name)
 
 	var entry lock.Skill
 	if skill.Local != "" {
-		entry, err = syncLocalSkill(repoRoot, skill, syncOptions{}, lockMap)
+		entry, err = syncLocalSkill(repoRoot, skill, opts, lockMap)
 	} else {
-		entry, err = syncRemoteSkill(repoRoot, skill, syncOptions{}, lockMap)
+		entry, err = syncRemoteSkill(repoRoot, skill, opts, lockMap)
 	}
 	if err != nil {
 		return err
 	}
+	if err := policyGate(policy, skill, entry.License, activeScope.vendorPath(repoRoot, skill.Name)); err != nil {
+		return err
+	}
+	if err := checkSkillMD(skill.Name, activeScope.vendorPath(repoRoot, skill.Name), true); err != nil {
+		return err
+	}
 
 	lockMap[skill.Name] = entry
 
@@ -458,21 +689,22 @@ func runSyncSingle(skill spec.SkillEntry) error {
 		}
 	}
 	sort.Slice(lockSkills, func(i, j int) bool { return lockSkills[i].Name < lockSkills[j].Name })
+	lockData.Normalize = opts.hash.Normalize
 	lockData.Skills = lockSkills
 
-	if err := lock.Write("skv.lock", lockData); err != nil {
+	if err := lock.Write(activeScope.lockPath(), lockData); err != nil {
 		return err
 	}
 
-	if err := linkSkill(repoRoot, skill.Name, excluded); err != nil {
+	if err := linkSkill(repoRoot, skill, entry, tools, opts.hash); err != nil {
 		return err
 	}
 
 	commit := entry.Commit
 	if commit == "" {
 		commit = "(local)"
-	} else if len(commit) > 7 {
-		commit = commit[:7]
+	} else {
+		commit = lock.ShortCommit(commit)
 	}
 	globalOutput.Success("Vendored %s (%s)", skill.Name, commit)
 
@@ -480,7 +712,7 @@ func runSyncSingle(skill spec.SkillEntry) error {
 }
 
 func runList(opts listOptions) error {
-	lockData, err := lock.Load("skv.lock")
+	lockData, err := lock.Load(activeScope.lockPath())
 	if err != nil {
 		return err
 	}
@@ -513,7 +745,7 @@ func runList(opts listOptions) error {
 
 	// Table output
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "NAME\tSOURCE\tREF\tCOMMIT")
+	fmt.Fprintln(w, "NAME\tSOURCE\tREF\tCOMMIT\tDESCRIPTION")
 	for _, skill := range lockData.Skills {
 		source := skill.Repo
 		ref := skill.Ref
@@ -523,6 +755,10 @@ func runList(opts listOptions) error {
 			source = skill.Local
 			ref = "(local)"
 			commit = "-"
+			if skill.ForkedFrom != nil {
+				ref = "(fork)"
+				commit = lock.ShortCommit(skill.ForkedFrom.Commit)
+			}
 		} else {
 			// Shorten source for display
 			source = strings.TrimPrefix(source, "https://")
@@ -534,27 +770,41 @@ func runList(opts listOptions) error {
 			if ref == "" {
 				ref = "(default)"
 			}
-			if len(commit) > 7 {
-				commit = commit[:7]
-			}
+			commit = lock.ShortCommit(commit)
 		}
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", skill.Name, source, ref, commit)
+		description := ""
+		if skill.Metadata != nil {
+			description = shortDescription(skill.Metadata.Description)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", skill.Name, source, ref, commit, description)
 	}
 	return w.Flush()
 }
 
+// shortDescription returns the first line of a SKILL.md description, cut to
+// fit a table column.
+func shortDescription(description string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(description), "\n")
+	if runes := []rune(line); len(runes) > maxListDescription {
+		line = string(runes[:maxListDescription-3]) + "..."
+	}
+	return line
+}
+
 func runRemove(name string) error {
-	specData, err := spec.Load("skv.cue")
+	specData, err := spec.Load(activeScope.specPath())
 	if err != nil {
 		return err
 	}
 
 	// Find and remove from spec
 	found := false
+	var removed spec.SkillEntry
 	var newSkills []spec.SkillEntry
 	for _, skill := range specData.Skills {
-		if skill.Name == name {
+		if specData.QualifiedName(skill) == name {
 			found = true
+			removed = skill
 			continue
 		}
 		newSkills = append(newSkills, skill)
@@ -563,37 +813,44 @@ func runRemove(name string) error {
 		return fmt.Errorf("skill %q not found in skv.cue", name)
 	}
 
-	repoRoot, err := os.Getwd()
+	repoRoot, err := activeScope.root()
 	if err != nil {
 		return err
 	}
 
 	// Remove vendored content
-	vendorPath := filepath.Join(repoRoot, ".skv", "skills", name)
+	vendorPath := activeScope.vendorPath(repoRoot, name)
 	if err := os.RemoveAll(vendorPath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to remove vendor directory: %w", err)
 	}
 
 	// Remove symlinks
-	links := []string{
-		filepath.Join(repoRoot, ".claude", "skills", name),
-		filepath.Join(repoRoot, ".codex", "skills", name),
-		filepath.Join(repoRoot, ".opencode", "skill", name),
+	tools, err := resolveTools(specData)
+	if err != nil {
+		return err
 	}
-	for _, link := range links {
-		if err := os.Remove(link); err != nil && !os.IsNotExist(err) {
-			return fmt.Errorf("failed to remove symlink %s: %w", link, err)
+	for _, tool := range tools {
+		link := filepath.Join(repoRoot, tool.Dir, name)
+		if !linksTool(removed, tool) {
+			// Leave anything skv did not put there in tools the skill doesn't target.
+			if _, err := removeManagedLink(repoRoot, name, link); err != nil {
+				return fmt.Errorf("failed to remove link %s: %w", link, err)
+			}
+			continue
+		}
+		if err := removeToolLink(link); err != nil {
+			return fmt.Errorf("failed to remove link %s: %w", link, err)
 		}
 	}
 
 	// Update spec
 	specData.Skills = newSkills
-	if err := spec.Write("skv.cue", specData); err != nil {
+	if err := spec.Write(activeScope.specPath(), specData); err != nil {
 		return err
 	}
 
 	// Update lock file
-	lockData, lockMap, err := loadLockOptional("skv.lock")
+	lockData, lockMap, err := loadLockOptional(activeScope.lockPath())
 	if err != nil {
 		return err
 	}
@@ -601,14 +858,14 @@ func runRemove(name string) error {
 
 	var lockSkills []lock.Skill
 	for _, skill := range newSkills {
-		if entry, ok := lockMap[skill.Name]; ok {
+		if entry, ok := lockMap[specData.QualifiedName(skill)]; ok {
 			lockSkills = append(lockSkills, entry)
 		}
 	}
 	sort.Slice(lockSkills, func(i, j int) bool { return lockSkills[i].Name < lockSkills[j].Name })
 	lockData.Skills = lockSkills
 
-	if err := lock.Write("skv.lock", lockData); err != nil {
+	if err := lock.Write(activeScope.lockPath(), lockData); err != nil {
 		return err
 	}
 
@@ -616,76 +873,166 @@ func runRemove(name string) error {
 	return nil
 }
 
-func runStatus() error {
-	specData, err := spec.Load("skv.cue")
+func runStatus(opts reportOptions) error {
+	if err := validateFormat(opts.format); err != nil {
+		return err
+	}
+
+	specData, err := loadSpec()
 	if err != nil {
 		return err
 	}
 
-	if len(specData.Skills) == 0 {
-		globalOutput.Info("No skills defined in skv.cue")
-		return nil
+	lockData, _, err := loadLockOptional(activeScope.lockPath())
+	if err != nil {
+		return err
 	}
 
-	lockData, lockMap, err := loadLockOptional("skv.lock")
+	repoRoot, err := activeScope.root()
 	if err != nil {
 		return err
 	}
-	_ = lockData
 
-	repoRoot, err := os.Getwd()
+	tools, err := resolveTools(specData)
 	if err != nil {
 		return err
 	}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	for _, skill := range specData.Skills {
-		status := "ok"
-		detail := ""
+	policy, err := loadPolicy(specData)
+	if err != nil {
+		return err
+	}
 
-		entry, inLock := lockMap[skill.Name]
-		vendorPath := filepath.Join(repoRoot, ".skv", "skills", skill.Name)
+	report := buildVerifyReport(repoRoot, specData, lockData, tools, policy)
+	if !activeScope.global {
+		globalNames, err := loadGlobalSpecNames()
+		if err != nil {
+			return err
+		}
+		for i, skill := range report.Skills {
+			_, report.Skills[i].ShadowsGlobal = globalNames[skill.Name]
+		}
+	}
+	if opts.format != formatText {
+		return writeReport(os.Stdout, opts.format, "status", report)
+	}
 
-		if !inLock {
-			status = "missing"
-			detail = "not in lock file"
-		} else {
-			// Check if vendored content exists
-			if _, err := os.Stat(vendorPath); os.IsNotExist(err) {
-				status = "missing"
-				detail = "vendor directory missing"
-			} else if err != nil {
-				status = "error"
-				detail = err.Error()
-			} else {
-				// Check checksum
-				checksum, err := hashDirWithTimeout(vendorPath)
-				if err != nil {
-					status = "error"
-					detail = err.Error()
-				} else if checksum != entry.Checksum {
-					status = "modified"
-					detail = "local changes detected"
-				} else {
-					// Format detail with ref info
-					if entry.Local != "" {
-						detail = "local"
-					} else {
-						ref := entry.Ref
-						if ref == "" {
-							ref = "default"
-						}
-						commit := entry.Commit
-						if len(commit) > 7 {
-							commit = commit[:7]
-						}
-						detail = fmt.Sprintf("%s @ %s", ref, commit)
-					}
-				}
+	if len(specData.Skills) == 0 {
+		globalOutput.Info("No skills defined in skv.cue")
+		return nil
+	}
+
+	unlinked := make(map[string][]string)
+	for _, finding := range report.Findings {
+		if finding.Category == verifyVendorLink {
+			unlinked[finding.Skill] = append(unlinked[finding.Skill], finding.Path)
+		}
+	}
+
+	targeted := make(map[string][]string)
+	for _, skill := range specData.Skills {
+		if len(skill.Tools) == 0 && len(skill.ExcludeTools) == 0 {
+			continue
+		}
+		var names []string
+		for _, tool := range tools {
+			if linksTool(skill, tool) {
+				names = append(names, tool.Name)
 			}
 		}
+		if len(names) == 0 {
+			names = append(names, "none")
+		}
+		targeted[skill.Name] = names
+	}
 
-		fmt.Fprintf(w, "%s\t%s\t%s\n", skill.Name, status, detail)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, skill := range report.Skills {
+		detail := skill.Detail
+		if names, ok := targeted[skill.Name]; ok {
+			detail = fmt.Sprintf("%s; tools: %s", detail, strings.Join(names, ", "))
+		}
+		if skill.ShadowsGlobal {
+			detail += "; shadows global skill"
+		}
+		if paths := unlinked[skill.Name]; len(paths) > 0 {
+			detail = fmt.Sprintf("%s; link broken: %s", detail, strings.Join(paths, ", "))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", skill.Name, skill.State, detail)
 	}
 	return w.Flush()
 }
+
+func runLockMerge(basePath, oursPath, theirsPath string) error {
+	base, err := loadMergeSide(basePath)
+	if err != nil {
+		return err
+	}
+	ours, err := loadMergeSide(oursPath)
+	if err != nil {
+		return err
+	}
+	theirs, err := loadMergeSide(theirsPath)
+	if err != nil {
+		return err
+	}
+
+	merged, conflicts := lock.Merge(base, ours, theirs)
+	if err := lock.Write(oursPath, merged); err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		for _, conflict := range conflicts {
+			globalOutput.Error("conflict: %s", conflict)
+		}
+		return fmt.Errorf("skv.lock merge has %d conflict(s); kept ours, resolve skv.cue and run skv sync", len(conflicts))
+	}
+	return nil
+}
+
+// loadMergeSide loads one side of a lock merge. Git passes an empty file
+// for the base when the sides share no ancestor version.
+func loadMergeSide(path string) (*lock.Lock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return &lock.Lock{}, nil
+	}
+	lockData, err := lock.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return lockData, nil
+}
+
+func runInstallMergeDriver() error {
+	repoRoot, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	if err := runGitCommand(repoRoot, "config", "merge.skv.name", mergeDriverName); err != nil {
+		return err
+	}
+	if err := runGitCommand(repoRoot, "config", "merge.skv.driver", mergeDriverCommand); err != nil {
+		return err
+	}
+
+	added, err := ensureGitAttribute(filepath.Join(repoRoot, ".gitattributes"), mergeDriverAttribute)
+	if err != nil {
+		return err
+	}
+	addedSum, err := ensureGitAttribute(filepath.Join(repoRoot, ".gitattributes"), sumMergeAttribute)
+	if err != nil {
+		return err
+	}
+	added = added || addedSum
+	if added {
+		globalOutput.Success("Installed skv merge driver; commit .gitattributes to share it")
+	} else {
+		globalOutput.Success("Installed skv merge driver")
+	}
+	return nil
+}
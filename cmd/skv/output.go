@@ -59,6 +59,11 @@ func (o *Output) Info(format string, args ...any) {
 	fmt.Fprintf(o.out, format+"\n", args...)
 }
 
+// Warn prints a warning (always shown).
+func (o *Output) Warn(format string, args ...any) {
+	fmt.Fprintf(o.err, "warning: "+format+"\n", args...)
+}
+
 // Error prints an error message (always shown).
 func (o *Output) Error(format string, args ...any) {
 	fmt.Fprintf(o.err, format+"\n", args...)
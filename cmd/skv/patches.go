@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/skill-vendor/skv/internal/fsutil"
+	"github.com/skill-vendor/skv/internal/lock"
+	"github.com/skill-vendor/skv/internal/spec"
+)
+
+// patchDir returns the directory patch globs are relative to: the spec's.
+func patchDir() string {
+	return filepath.Dir(activeScope.specPath())
+}
+
+// resolvePatches expands skill's patch globs in the order they are listed,
+// each glob's matches sorted, and digests every patch file.
+func resolvePatches(skill spec.SkillEntry) ([]lock.Patch, error) {
+	base := patchDir()
+	var patches []lock.Patch
+	seen := make(map[string]bool)
+	for _, pattern := range skill.Patches {
+		if filepath.IsAbs(pattern) {
+			return nil, fmt.Errorf("skill %q: patch pattern must be relative: %s", skill.Name, pattern)
+		}
+		matches, err := filepath.Glob(filepath.Join(base, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, fmt.Errorf("skill %q: invalid patch pattern %q: %w", skill.Name, pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("skill %q: patch pattern %q matches no files", skill.Name, pattern)
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			rel := relPath(base, match)
+			if seen[rel] {
+				continue
+			}
+			seen[rel] = true
+			data, err := os.ReadFile(match)
+			if err != nil {
+				return nil, err
+			}
+			patches = append(patches, lock.Patch{Path: rel, Checksum: fmt.Sprintf("%x", sha256.Sum256(data))})
+		}
+	}
+	return patches, nil
+}
+
+// samePatches reports whether two patch lists name the same files in the same
+// order with the same content.
+func samePatches(a, b []lock.Patch) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// applyPatches applies patches in order to dir. Every patch is attempted so
+// that all patches that no longer apply are reported together.
+func applyPatches(name, dir string, patches []lock.Patch) error {
+	failed := 0
+	for _, patch := range patches {
+		if err := applyPatch(dir, filepath.Join(patchDir(), filepath.FromSlash(patch.Path))); err != nil {
+			failed++
+			globalOutput.Error("%s: patch %s no longer applies: %v", name, patch.Path, err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d patch(es) for %q no longer apply; update or remove them", failed, name)
+	}
+	return nil
+}
+
+// applyPatch runs git apply in dir. The ceiling keeps git from treating dir
+// as part of an enclosing repository, so paths resolve relative to dir.
+func applyPatch(dir, patchPath string) error {
+	abs, err := filepath.Abs(patchPath)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "apply", "--whitespace=nowarn", abs)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_CEILING_DIRECTORIES="+filepath.Dir(dir))
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("git apply timed out")
+	}
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			msg = err.Error()
+		}
+		return fmt.Errorf("%s", strings.ReplaceAll(msg, "\n", "; "))
+	}
+	return nil
+}
+
+// vendorCheckout copies the files of the skill at srcPath that its filter
+// keeps into vendorPath, with its patches applied, and records the checksums,
+// patches and filter on entry. The patched copy must pass the policy and
+// audit gates before it replaces vendorPath.
+func vendorCheckout(srcPath, vendorPath string, skill spec.SkillEntry, opts syncOptions, entry *lock.Skill) error {
+	hashOpts := opts.hash
+	f, err := skillFilter(skill, srcPath)
+	if err != nil {
+		return err
+	}
+	if err := validateSkillFiles(srcPath, f); err != nil {
+		return err
+	}
+	patches, err := resolvePatches(skill)
+	if err != nil {
+		return err
+	}
+	if len(patches) > 0 {
+		if entry.UpstreamChecksum, err = hashDirWithTimeout(srcPath, withFilter(hashOpts, f)); err != nil {
+			return err
+		}
+		entry.Patches = patches
+	}
+	entry.Filter = f
+	if err := copyDirAtomicWith(srcPath, vendorPath, f, func(dir string) error {
+		if err := applyPatches(skill.Name, dir, patches); err != nil {
+			return err
+		}
+		if err := policyGate(opts.policy, skill, entry.License, dir); err != nil {
+			return err
+		}
+		return auditGate(skill.Name, dir, opts.audit)
+	}); err != nil {
+		return err
+	}
+	if err := validateSkillDir(vendorPath); err != nil {
+		return err
+	}
+	entry.Checksum, entry.Files, err = hashSkillDir(vendorPath, hashOpts)
+	entry.Metadata = skillMetadata(vendorPath)
+	return err
+}
+
+// runPatchCreate turns local edits to a vendored remote skill into a new patch
+// file under patches/<name>/, adds it to the skill's patches, and relocks the
+// skill with the edited content.
+func runPatchCreate(name string) error {
+	repoRoot, err := activeScope.root()
+	if err != nil {
+		return err
+	}
+	specData, err := spec.Load(activeScope.specPath())
+	if err != nil {
+		return err
+	}
+	index := -1
+	for i, skill := range specData.Skills {
+		if specData.QualifiedName(skill) == name {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return fmt.Errorf("skill %q not found in spec", name)
+	}
+	skill := specData.Skills[index]
+	if skill.Local != "" {
+		return fmt.Errorf("skill %q is local; edit it directly instead of patching", name)
+	}
+
+	lockData, lockMap, err := loadLockRequired(activeScope.lockPath())
+	if err != nil {
+		return err
+	}
+	if err := ensureChecksumMode(specData, lockData); err != nil {
+		return err
+	}
+	hashOpts := checksumOptions(specData)
+
+	locked := skill
+	locked.Name = name
+	if locked.Path, err = cleanSubpath(locked.Path); err != nil {
+		return err
+	}
+	entry, ok := lockMap[name]
+	if !ok || !lockMatchesSpec(entry, locked) {
+		return fmt.Errorf("lock entry for %q does not match spec; run skv sync first", name)
+	}
+	patches, err := resolvePatches(locked)
+	if err != nil {
+		return err
+	}
+	if !samePatches(patches, entry.Patches) {
+		return fmt.Errorf("patches for %q changed since the last sync; run skv sync first", name)
+	}
+	vendorPath := activeScope.vendorPath(repoRoot, name)
+	if err := ensureSkill(vendorPath); err != nil {
+		return fmt.Errorf("vendored content for %q is missing; run skv sync first", name)
+	}
+	if err := validateSkillDir(vendorPath); err != nil {
+		return err
+	}
+
+	// Rebuild what sync would vendor, then diff it against the edited copy.
+	cloneDir, err := cloneLockedCommit(locked, entry.Commit, "")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(cloneDir)
+	srcPath := filepath.Join(cloneDir, locked.Path)
+	baseline, err := os.MkdirTemp("", "skv-patch-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(baseline)
+	if err := fsutil.CopyDir(srcPath, baseline, entry.Filter); err != nil {
+		return err
+	}
+	if err := applyPatches(name, baseline, patches); err != nil {
+		return err
+	}
+
+	baseTree, _, err := writeLocalTree(cloneDir, baseline, hashOpts)
+	if err != nil {
+		return err
+	}
+	editedTree, _, err := writeLocalTree(cloneDir, vendorPath, hashOpts)
+	if err != nil {
+		return err
+	}
+	diff, err := runGitCommandOutput(cloneDir, "diff", "--binary", "--no-color", "--no-ext-diff",
+		"--src-prefix=a/", "--dst-prefix=b/", baseTree, editedTree)
+	if err != nil {
+		return err
+	}
+	if len(diff) == 0 {
+		return fmt.Errorf("no local changes to %q", name)
+	}
+
+	dir := filepath.Join(patchDir(), "patches", name)
+	if err := fsutil.EnsureDir(dir); err != nil {
+		return err
+	}
+	var patchPath string
+	for n := 1; ; n++ {
+		patchPath = filepath.Join(dir, fmt.Sprintf("%04d.patch", n))
+		if !exists(patchPath) {
+			break
+		}
+	}
+	if err := os.WriteFile(patchPath, diff, 0o644); err != nil {
+		return err
+	}
+
+	pattern := filepath.ToSlash(filepath.Join("patches", name, "*.patch"))
+	covered := false
+	for _, existing := range skill.Patches {
+		if ok, _ := filepath.Match(filepath.FromSlash(existing), filepath.Join("patches", name, filepath.Base(patchPath))); ok {
+			covered = true
+			break
+		}
+	}
+	if !covered {
+		skill.Patches = append(skill.Patches, pattern)
+		specData.Skills[index] = skill
+		if err := spec.Write(activeScope.specPath(), specData); err != nil {
+			return err
+		}
+	}
+
+	locked.Patches = skill.Patches
+	if entry.Patches, err = resolvePatches(locked); err != nil {
+		return err
+	}
+	if entry.UpstreamChecksum == "" {
+		if entry.UpstreamChecksum, err = hashDirWithTimeout(srcPath, withFilter(hashOpts, entry.Filter)); err != nil {
+			return err
+		}
+	}
+	if entry.Checksum, entry.Files, err = hashSkillDir(vendorPath, hashOpts); err != nil {
+		return err
+	}
+	entry.Metadata = skillMetadata(vendorPath)
+	for i := range lockData.Skills {
+		if lockData.Skills[i].Name == name {
+			lockData.Skills[i] = entry
+		}
+	}
+	if err := lock.Write(activeScope.lockPath(), lockData); err != nil {
+		return err
+	}
+
+	tools, err := resolveTools(specData)
+	if err != nil {
+		return err
+	}
+	if err := linkSkill(repoRoot, locked, entry, tools, hashOpts); err != nil {
+		return err
+	}
+	globalOutput.Success("Created %s for %s", relPath(repoRoot, patchPath), name)
+	return nil
+}
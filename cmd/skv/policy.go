@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/skill-vendor/skv/internal/license"
+	"github.com/skill-vendor/skv/internal/lock"
+	"github.com/skill-vendor/skv/internal/spec"
+)
+
+// Policy rules, reported with each violation.
+const (
+	policyRepo       = "repo"
+	policyPinnedRef  = "pinned-ref"
+	policyLicense    = "license"
+	policyExecutable = "executable"
+)
+
+// policyEnv names a shared policy file that is unified with the policy in
+// skv.cue and skv.policy.cue.
+const policyEnv = "SKV_POLICY"
+
+var errPolicyViolation = errors.New("policy violation")
+
+type policyViolation struct {
+	Skill   string
+	Rule    string
+	Path    string // file inside the skill, for content violations
+	Message string
+}
+
+func (v policyViolation) String() string {
+	return fmt.Sprintf("%s: %s (%s)", v.Skill, v.Message, v.Rule)
+}
+
+// loadPolicy returns the policy in effect for specData: its policy block,
+// skv.policy.cue next to the spec, and $SKV_POLICY, unified. It returns nil
+// when none of them sets a policy.
+func loadPolicy(specData *spec.Spec) (*spec.Policy, error) {
+	var files []string
+	local := filepath.Join(filepath.Dir(activeScope.specPath()), spec.PolicyFile)
+	if _, err := os.Stat(local); err == nil {
+		files = append(files, local)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if shared := os.Getenv(policyEnv); shared != "" {
+		files = append(files, shared)
+	}
+	return spec.LoadPolicy(specData, files...)
+}
+
+// sourceViolations checks where a remote skill comes from: its repo must be
+// allowed and, unless the repo is trusted, its ref must pin a commit.
+func sourceViolations(p *spec.Policy, skill spec.SkillEntry) []policyViolation {
+	if p == nil || skill.Repo == "" {
+		return nil
+	}
+	var violations []policyViolation
+	if len(p.Repos) > 0 && !matchRepo(p.Repos, skill.Repo) {
+		violations = append(violations, policyViolation{
+			Skill:   skill.Name,
+			Rule:    policyRepo,
+			Message: fmt.Sprintf("repo %s is not allowed (allowed: %s)", skill.Repo, strings.Join(p.Repos, ", ")),
+		})
+	}
+	if p.RequirePinned && !isCommitRef(skill.Ref) && !matchRepo(p.Trusted, skill.Repo) {
+		ref := fmt.Sprintf("ref %q is not a commit", skill.Ref)
+		if skill.Ref == "" {
+			ref = "no ref is set"
+		}
+		violations = append(violations, policyViolation{
+			Skill:   skill.Name,
+			Rule:    policyPinnedRef,
+			Message: ref + "; skills from untrusted repos must pin a commit",
+		})
+	}
+	return violations
+}
+
+// contentViolations checks what a skill vendors from dir: the license of a
+// remote skill must be allowed, and no file may be executable.
+func contentViolations(p *spec.Policy, skill spec.SkillEntry, detected *lock.License, dir string) ([]policyViolation, error) {
+	if p == nil {
+		return nil, nil
+	}
+	var violations []policyViolation
+	if len(p.Licenses) > 0 && skill.Repo != "" {
+		var message string
+		switch {
+		case detected == nil:
+			message = "no license found"
+		case detected.SPDX == "" || detected.Confidence < license.MinConfidence:
+			// A weak match is as good as none for an allowlist.
+			message = fmt.Sprintf("license in %s is not recognized", detected.Path)
+		case !license.Satisfies(detected.SPDX, p.Licenses):
+			message = fmt.Sprintf("license %s is not allowed", detected.SPDX)
+		}
+		if message != "" {
+			violations = append(violations, policyViolation{
+				Skill:   skill.Name,
+				Rule:    policyLicense,
+				Message: fmt.Sprintf("%s (allowed: %s)", message, strings.Join(p.Licenses, ", ")),
+			})
+		}
+	}
+	if p.NoExecutables {
+		err := walkSkillFiles(dir, nil, func(_, rel string, info fs.FileInfo) error {
+			if info.Mode().IsRegular() && info.Mode().Perm()&0o111 != 0 {
+				violations = append(violations, policyViolation{
+					Skill:   skill.Name,
+					Rule:    policyExecutable,
+					Path:    rel,
+					Message: fmt.Sprintf("%s is executable; executable files are not allowed", rel),
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return violations, nil
+}
+
+// policyGate checks the content of skill in dir against p and fails with a
+// report of every violation.
+func policyGate(p *spec.Policy, skill spec.SkillEntry, detected *lock.License, dir string) error {
+	violations, err := contentViolations(p, skill, detected, dir)
+	if err != nil {
+		return err
+	}
+	return reportPolicy(violations)
+}
+
+// reportPolicy prints violations and returns an error wrapping
+// errPolicyViolation if there are any.
+func reportPolicy(violations []policyViolation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	for _, v := range violations {
+		globalOutput.Error("policy: %s", v)
+	}
+	return fmt.Errorf("found %d %w(s)", len(violations), errPolicyViolation)
+}
+
+// matchRepo reports whether repo matches one of patterns. Both are compared
+// as host/path, so patterns may be written with or without a scheme.
+func matchRepo(patterns []string, repo string) bool {
+	id := repoID(repo)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(repoID(pattern), id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// repoID reduces a repo URL to host/path: the scheme, user, and a trailing
+// .git are dropped, and scp-like git@host:org/repo becomes host/org/repo.
+func repoID(repo string) string {
+	id := repo
+	if i := strings.Index(id, "://"); i >= 0 {
+		id = id[i+3:]
+	} else if at := strings.Index(id, "@"); at >= 0 && strings.Contains(id[at:], ":") {
+		id = strings.Replace(id[at+1:], ":", "/", 1)
+	}
+	if at := strings.Index(id, "@"); at >= 0 && at < strings.Index(id+"/", "/") {
+		id = id[at+1:]
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(id, "/"), ".git")
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skill-vendor/skv/internal/lock"
+	"github.com/skill-vendor/skv/internal/spec"
+)
+
+func TestRepoID(t *testing.T) {
+	cases := map[string]string{
+		"https://github.com/acme/skills":          "github.com/acme/skills",
+		"https://github.com/acme/skills.git":      "github.com/acme/skills",
+		"https://token@github.com/acme/skills/":   "github.com/acme/skills",
+		"ssh://git@github.com/acme/skills.git":    "github.com/acme/skills",
+		"git@github.com:acme/skills.git":          "github.com/acme/skills",
+		"github.com/acme/skills":                  "github.com/acme/skills",
+		"file:///srv/git/skills":                  "/srv/git/skills",
+		"https://gitlab.example.com:8443/a/b.git": "gitlab.example.com:8443/a/b",
+	}
+	for repo, want := range cases {
+		if got := repoID(repo); got != want {
+			t.Errorf("repoID(%q) = %q, want %q", repo, got, want)
+		}
+	}
+}
+
+func TestMatchRepo(t *testing.T) {
+	patterns := []string{"github.com/acme/*", "https://gitlab.com/team/tools.git"}
+	cases := map[string]bool{
+		"https://github.com/acme/skills":     true,
+		"git@github.com:acme/review.git":     true,
+		"https://github.com/acme/skills/sub": false,
+		"https://github.com/other/skills":    false,
+		"https://gitlab.com/team/tools":      true,
+	}
+	for repo, want := range cases {
+		if got := matchRepo(patterns, repo); got != want {
+			t.Errorf("matchRepo(%q) = %v, want %v", repo, got, want)
+		}
+	}
+	if matchRepo(nil, "https://github.com/acme/skills") {
+		t.Error("matchRepo with no patterns matched")
+	}
+}
+
+func TestContentViolationsLicenseConfidence(t *testing.T) {
+	p := &spec.Policy{Licenses: []string{"MIT"}}
+	skill := spec.SkillEntry{Name: "review", Repo: "https://github.com/acme/skills"}
+	cases := map[float64]string{
+		1:    "",
+		0.95: "",
+		0.5:  "license in LICENSE is not recognized (allowed: MIT)",
+		0:    "license in LICENSE is not recognized (allowed: MIT)",
+	}
+	for confidence, want := range cases {
+		detected := &lock.License{SPDX: "MIT", Path: "LICENSE", Confidence: confidence}
+		violations, err := contentViolations(p, skill, detected, t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, v := range violations {
+			got = append(got, v.Message)
+		}
+		if strings.Join(got, "; ") != want {
+			t.Errorf("confidence %v: violations = %q, want %q", confidence, got, want)
+		}
+	}
+}
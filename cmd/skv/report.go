@@ -0,0 +1,419 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/skill-vendor/skv/internal/dirhash"
+	"github.com/skill-vendor/skv/internal/lock"
+	"github.com/skill-vendor/skv/internal/spec"
+)
+
+// Output formats accepted by --format on verify and status.
+const (
+	formatText  = "text"
+	formatJSON  = "json"
+	formatSARIF = "sarif"
+	formatJUnit = "junit"
+)
+
+// Skill states reported by status and verify.
+const (
+	stateOK       = "ok"
+	stateModified = "modified"
+	stateMissing  = "missing"
+	stateExtra    = "extra"
+)
+
+type fileDiff struct {
+	Path   string `json:"path"`
+	Change string `json:"change"` // added, removed, or modified
+}
+
+type skillReport struct {
+	Name             string     `json:"name"`
+	State            string     `json:"state"`
+	Detail           string     `json:"detail,omitempty"`
+	ExpectedChecksum string     `json:"expectedChecksum,omitempty"`
+	ActualChecksum   string     `json:"actualChecksum,omitempty"`
+	Files            []fileDiff `json:"files,omitempty"`
+	ShadowsGlobal    bool       `json:"shadowsGlobal,omitempty"` // status only: a global skill has the same name
+
+	// problem is set when the vendored content does not match the lock entry.
+	problem string
+}
+
+type verifyReport struct {
+	OK       bool            `json:"ok"`
+	Skills   []skillReport   `json:"skills"`
+	Findings []verifyFinding `json:"findings"`
+}
+
+func validateFormat(format string) error {
+	switch format {
+	case formatText, formatJSON, formatSARIF, formatJUnit:
+		return nil
+	}
+	return usageErrorf("unknown format %q (want text, json, sarif, or junit)", format)
+}
+
+func buildVerifyReport(repoRoot string, specData *spec.Spec, lockData *lock.Lock, tools []agentTool, policy *spec.Policy) verifyReport {
+	skills := buildSkillReports(repoRoot, specData, lockData)
+	findings := collectVerifyFindings(repoRoot, specData, lockData, tools, skills, policy)
+	if findings == nil {
+		findings = []verifyFinding{}
+	}
+	return verifyReport{OK: len(findings) == 0, Skills: skills, Findings: findings}
+}
+
+// buildSkillReports inspects every skill named by the spec or the lock, in
+// spec order followed by lock-only entries.
+func buildSkillReports(repoRoot string, specData *spec.Spec, lockData *lock.Lock) []skillReport {
+	lockMap := indexLock(lockData)
+	hashOpts := lockChecksumOptions(lockData)
+	reports := []skillReport{}
+	seen := make(map[string]struct{})
+
+	for _, skill := range specData.Skills {
+		if _, dup := seen[skill.Name]; dup || skill.Name == "" {
+			continue
+		}
+		seen[skill.Name] = struct{}{}
+		entry, ok := lockMap[skill.Name]
+		if !ok {
+			reports = append(reports, skillReport{Name: skill.Name, State: stateMissing, Detail: "not in lock file"})
+			continue
+		}
+		reports = append(reports, inspectVendored(repoRoot, entry, hashOpts))
+	}
+
+	var extras []string
+	for name := range lockMap {
+		if _, ok := seen[name]; !ok && name != "" {
+			extras = append(extras, name)
+		}
+	}
+	sort.Strings(extras)
+	for _, name := range extras {
+		report := inspectVendored(repoRoot, lockMap[name], hashOpts)
+		report.State = stateExtra
+		report.Detail = "not in skv.cue"
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// inspectVendored compares the vendored directory for entry against its lock checksum.
+func inspectVendored(repoRoot string, entry lock.Skill, hashOpts dirhash.Options) skillReport {
+	report := skillReport{Name: entry.Name, ExpectedChecksum: entry.Checksum}
+	vendorPath := activeScope.vendorPath(repoRoot, entry.Name)
+
+	if _, err := os.Stat(vendorPath); os.IsNotExist(err) {
+		report.State = stateMissing
+		report.Detail = "vendor directory missing"
+		report.problem = fmt.Sprintf("vendored content for %q is missing", entry.Name)
+		return report
+	}
+
+	checksum, files, err := hashSkillDir(vendorPath, hashOpts)
+	if err != nil {
+		report.State = stateModified
+		report.Detail = err.Error()
+		report.problem = err.Error()
+		return report
+	}
+	report.ActualChecksum = checksum
+	if entry.Files != nil {
+		report.Files = diffFileSums(entry.Files, files)
+	}
+
+	if err := ensureSkill(vendorPath); err != nil {
+		report.State = stateModified
+		report.Detail = err.Error()
+		report.problem = err.Error()
+		return report
+	}
+	if err := validateSkillDir(vendorPath); err != nil {
+		report.State = stateModified
+		report.Detail = err.Error()
+		report.problem = err.Error()
+		return report
+	}
+
+	if checksum != entry.Checksum {
+		report.State = stateModified
+		report.Detail = "local changes detected"
+		report.problem = fmt.Sprintf("vendored content mismatch for %q (expected %s, got %s)", entry.Name, entry.Checksum, checksum)
+		return report
+	}
+
+	report.State = stateOK
+	report.Detail = describeLockEntry(entry)
+	return report
+}
+
+// describeLockEntry summarizes where a locked skill came from.
+func describeLockEntry(entry lock.Skill) string {
+	if entry.Local != "" {
+		return "local"
+	}
+	ref := entry.Ref
+	if ref == "" {
+		ref = "default"
+	}
+	return fmt.Sprintf("%s @ %s", ref, lock.ShortCommit(entry.Commit))
+}
+
+// diffFileSums lists files added, removed, or changed relative to the lock's per-file digests.
+func diffFileSums(expected, actual map[string]string) []fileDiff {
+	var diffs []fileDiff
+	for path, sum := range expected {
+		got, ok := actual[path]
+		switch {
+		case !ok:
+			diffs = append(diffs, fileDiff{Path: path, Change: "removed"})
+		case got != sum:
+			diffs = append(diffs, fileDiff{Path: path, Change: "modified"})
+		}
+	}
+	for path := range actual {
+		if _, ok := expected[path]; !ok {
+			diffs = append(diffs, fileDiff{Path: path, Change: "added"})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs
+}
+
+// writeReport renders report in a machine-readable format for the named command.
+func writeReport(w io.Writer, format, command string, report verifyReport) error {
+	switch format {
+	case formatJSON:
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case formatSARIF:
+		return writeSARIF(w, report)
+	case formatJUnit:
+		return writeJUnit(w, command, report)
+	}
+	return fmt.Errorf("unsupported format %q", format)
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+var verifyRules = []sarifRule{
+	{ID: verifySpecLock, ShortDescription: sarifMessage{Text: "skv.lock does not match skv.cue"}},
+	{ID: verifyLockVendor, ShortDescription: sarifMessage{Text: "Vendored skill content does not match skv.lock"}},
+	{ID: verifyVendorLink, ShortDescription: sarifMessage{Text: "Tool link does not point at the vendored skill"}},
+	{ID: verifyPolicy, ShortDescription: sarifMessage{Text: "Skill violates the policy"}},
+	{ID: verifyNotices, ShortDescription: sarifMessage{Text: "Third-party notices are missing or out of date with skv.lock"}},
+	{ID: verifyLockSignature, ShortDescription: sarifMessage{Text: "skv.lock is not signed by an allowed key"}},
+}
+
+func writeSARIF(w io.Writer, report verifyReport) error {
+	skills := make(map[string]skillReport, len(report.Skills))
+	for _, skill := range report.Skills {
+		skills[skill.Name] = skill
+	}
+
+	results := []sarifResult{}
+	for _, finding := range report.Findings {
+		skill := skills[finding.Skill]
+		if finding.Category == verifyLockVendor && len(skill.Files) > 0 {
+			// Point at each differing file so review tools can annotate it inline.
+			for _, file := range skill.Files {
+				results = append(results, sarifResult{
+					RuleID:    finding.Category,
+					Level:     "error",
+					Message:   sarifMessage{Text: fmt.Sprintf("%s: %s %s (lock checksum %s)", finding.Skill, file.Path, file.Change, skill.ExpectedChecksum)},
+					Locations: sarifLocations(finding.Path + "/" + file.Path),
+				})
+			}
+			continue
+		}
+		results = append(results, sarifResult{
+			RuleID:    finding.Category,
+			Level:     "error",
+			Message:   sarifMessage{Text: finding.String()},
+			Locations: sarifLocations(finding.Path),
+		})
+	}
+
+	log := sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "skv",
+				Version:        version,
+				InformationURI: "https://github.com/skill-vendor/skv",
+				Rules:          verifyRules,
+			}},
+			Results: results,
+		}},
+	}
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+func sarifLocations(path string) []sarifLocation {
+	if path == "" {
+		return nil
+	}
+	return []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: path}}}}
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func writeJUnit(w io.Writer, command string, report verifyReport) error {
+	bySkill := make(map[string][]verifyFinding)
+	for _, finding := range report.Findings {
+		bySkill[finding.Skill] = append(bySkill[finding.Skill], finding)
+	}
+
+	suite := junitTestSuite{Name: "skv " + command}
+	addCase := func(name string, findings []verifyFinding, skill *skillReport) {
+		tc := junitTestCase{Name: name, Classname: "skv." + command}
+		failed := len(findings) > 0 || (skill != nil && skill.State != stateOK)
+		if failed {
+			var lines []string
+			for _, finding := range findings {
+				lines = append(lines, finding.String())
+			}
+			message := findings[0].Message
+			if skill != nil {
+				message = fmt.Sprintf("%s is %s", skill.Name, skill.State)
+				for _, file := range skill.Files {
+					lines = append(lines, fmt.Sprintf("%s %s", file.Change, file.Path))
+				}
+			}
+			tc.Failure = &junitFailure{Message: message, Type: "skv", Text: strings.Join(lines, "\n")}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, tc)
+		suite.Tests++
+	}
+
+	if global := bySkill[""]; len(global) > 0 {
+		addCase("skv.lock", global, nil)
+	}
+	for i := range report.Skills {
+		skill := &report.Skills[i]
+		addCase(skill.Name, bySkill[skill.Name], skill)
+		delete(bySkill, skill.Name)
+	}
+	// Findings about skills skv no longer knows, such as stale generated
+	// notices, get a case of their own.
+	var others []string
+	for name := range bySkill {
+		if name != "" {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	for _, name := range others {
+		addCase(name, bySkill[name], nil)
+	}
+
+	suites := junitTestSuites{
+		Name:     suite.Name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/skill-vendor/skv/internal/lock"
+	"github.com/skill-vendor/skv/internal/sbom"
+)
+
+type sbomOptions struct {
+	format string
+	output string
+}
+
+// sourceDateEpochEnv fixes the SBOM timestamp for reproducible output, per
+// https://reproducible-builds.org/specs/source-date-epoch/.
+const sourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+// runSBOM writes an SBOM of the skills in skv.lock to opts.output, or to
+// stdout when it is empty.
+func runSBOM(opts sbomOptions) error {
+	if opts.format != sbom.CycloneDX && opts.format != sbom.SPDX {
+		return usageErrorf("--format must be %s or %s", sbom.CycloneDX, sbom.SPDX)
+	}
+	repoRoot, err := activeScope.root()
+	if err != nil {
+		return err
+	}
+	lockData, err := lock.Load(activeScope.lockPath())
+	if err != nil {
+		return err
+	}
+
+	created := time.Now()
+	if epoch := os.Getenv(sourceDateEpochEnv); epoch != "" {
+		seconds, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %w", sourceDateEpochEnv, err)
+		}
+		created = time.Unix(seconds, 0)
+	}
+	name := filepath.Base(repoRoot)
+	if activeScope.global {
+		name = "skv-global"
+	}
+	data, err := sbom.Generate(opts.format, sbom.Document{Name: name, ToolVersion: version, Created: created}, lockData)
+	if err != nil {
+		return err
+	}
+
+	if opts.output == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(opts.output, data, 0o644); err != nil {
+		return err
+	}
+	globalOutput.Success("Wrote %s SBOM of %d skill(s) to %s", opts.format, len(lockData.Skills), opts.output)
+	return nil
+}
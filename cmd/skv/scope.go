@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// scope locates the spec, lock, vendored skills and tool directories a command
+// works on. The zero value is the repo scope rooted at the working directory;
+// the global scope keeps its spec and lock under $XDG_CONFIG_HOME/skv, vendors
+// into $XDG_DATA_HOME/skv and links into agent directories under $HOME.
+type scope struct {
+	global    bool
+	home      string
+	configDir string
+	dataDir   string
+}
+
+var activeScope = &scope{}
+
+// newGlobalScope resolves the directories used by --global.
+func newGlobalScope() (*scope, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("global scope requires a home directory: %w", err)
+	}
+	return &scope{
+		global:    true,
+		home:      home,
+		configDir: filepath.Join(xdgDir("XDG_CONFIG_HOME", home, ".config"), "skv"),
+		dataDir:   filepath.Join(xdgDir("XDG_DATA_HOME", home, ".local", "share"), "skv"),
+	}, nil
+}
+
+// xdgDir returns $env if it is set to an absolute path, per the XDG base
+// directory spec, and home joined with fallback otherwise.
+func xdgDir(env, home string, fallback ...string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(append([]string{home}, fallback...)...)
+}
+
+func (s *scope) specPath() string {
+	if s.global {
+		return filepath.Join(s.configDir, "skv.cue")
+	}
+	return "skv.cue"
+}
+
+func (s *scope) lockPath() string {
+	if s.global {
+		return filepath.Join(s.configDir, "skv.lock")
+	}
+	return "skv.lock"
+}
+
+// root returns the directory tool directories and local skill paths are
+// relative to: the repo (working directory) or the home directory.
+func (s *scope) root() (string, error) {
+	if s.global {
+		return s.home, nil
+	}
+	return os.Getwd()
+}
+
+// vendorRoot returns the directory vendored skills are stored in.
+func (s *scope) vendorRoot(root string) string {
+	if s.global {
+		return filepath.Join(s.dataDir, "skills")
+	}
+	return filepath.Join(root, ".skv", "skills")
+}
+
+func (s *scope) vendorPath(root, name string) string {
+	return filepath.Join(s.vendorRoot(root), name)
+}
+
+// describe names the scope in user-facing messages.
+func (s *scope) describe() string {
+	if s.global {
+		return s.configDir
+	}
+	return "current directory"
+}
+
+// loadGlobalSpecNames returns the names of skills in the global spec, or nil
+// if there is no global spec.
+func loadGlobalSpecNames() (map[string]struct{}, error) {
+	global, err := newGlobalScope()
+	if err != nil {
+		return nil, nil
+	}
+	if _, err := os.Stat(global.specPath()); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	specData, err := loadSpecAt(global.specPath())
+	if err != nil {
+		return nil, fmt.Errorf("global spec: %w", err)
+	}
+	names := make(map[string]struct{}, len(specData.Skills))
+	for _, skill := range specData.Skills {
+		names[skill.Name] = struct{}{}
+	}
+	return names, nil
+}
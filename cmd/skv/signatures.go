@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/skill-vendor/skv/internal/lock"
+	"github.com/skill-vendor/skv/internal/spec"
+)
+
+// sshKeyTypes prefix an SSH public key written inline in verify.keys.
+var sshKeyTypes = []string{"ssh-", "ecdsa-sha2-", "sk-ssh-", "sk-ecdsa-"}
+
+var sshSignerPattern = regexp.MustCompile(`with \S+ key (SHA256:\S+)`)
+
+// signatureConfig returns the signature settings in effect for skill, its own
+// fields taking precedence over the spec-wide ones, or nil unless signatures
+// are required.
+func signatureConfig(skill spec.SkillEntry, global *spec.Verify) *spec.Verify {
+	var v spec.Verify
+	for _, layer := range []*spec.Verify{global, skill.Verify} {
+		if layer == nil {
+			continue
+		}
+		if layer.Signatures != "" {
+			v.Signatures = layer.Signatures
+		}
+		if len(layer.Keys) > 0 {
+			v.Keys = layer.Keys
+		}
+	}
+	if skill.Local != "" || v.Signatures != spec.SignaturesRequired {
+		return nil
+	}
+	return &v
+}
+
+// verifySignature checks that commit in cloneDir, or a tag pointing at it, is
+// signed by one of the keys in config, and returns the signer. Only those
+// keys are trusted: the user's own keyring and allowed-signers file are not
+// consulted.
+func verifySignature(name, cloneDir, commit string, config *spec.Verify) (*lock.Signer, error) {
+	if len(config.Keys) == 0 {
+		return nil, fmt.Errorf("%s: signatures are required but verify.keys is empty", name)
+	}
+	keys, err := newKeyring(config.Keys)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	defer keys.close()
+
+	signer, detail := keys.verify(cloneDir, "verify-commit", commit)
+	if signer != nil {
+		return signer, nil
+	}
+	out, err := runGitCommandOutput(cloneDir, "tag", "--points-at", commit)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range strings.Fields(string(out)) {
+		if signer, _ := keys.verify(cloneDir, "verify-tag", tag); signer != nil {
+			signer.Tag = tag
+			return signer, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: commit %s is not signed by an allowed key, nor is any tag pointing at it (%s)", name, lock.ShortCommit(commit), detail)
+}
+
+// keyring holds the allowed keys in the form git verifies against: an SSH
+// allowed-signers file and a private GnuPG home.
+type keyring struct {
+	dir            string
+	allowedSigners string
+	gnupgHome      string
+}
+
+// newKeyring writes keys to a temporary keyring. Each key is an inline SSH
+// public key or a path, relative to the spec, of an armored GPG public key or
+// an SSH allowed-signers file.
+func newKeyring(keys []string) (*keyring, error) {
+	dir, err := os.MkdirTemp("", "skv-keys-*")
+	if err != nil {
+		return nil, err
+	}
+	k := &keyring{
+		dir:            dir,
+		allowedSigners: filepath.Join(dir, "allowed_signers"),
+		gnupgHome:      filepath.Join(dir, "gnupg"),
+	}
+	if err := os.Mkdir(k.gnupgHome, 0o700); err != nil {
+		k.close()
+		return nil, err
+	}
+
+	signers, gpgKeys, err := allowedSigners(keys, "git")
+	if err != nil {
+		k.close()
+		return nil, fmt.Errorf("verify.keys: %w", err)
+	}
+	for _, path := range gpgKeys {
+		if err := k.importGPG(path); err != nil {
+			k.close()
+			return nil, fmt.Errorf("verify.keys: %s: %w", path, err)
+		}
+	}
+	if err := os.WriteFile(k.allowedSigners, []byte(signers), 0o600); err != nil {
+		k.close()
+		return nil, err
+	}
+	return k, nil
+}
+
+// allowedSigners returns the SSH keys among keys as allowed-signers lines for
+// namespace, and the paths of the armored GPG keys among them. Relative paths
+// are resolved against the spec's directory.
+func allowedSigners(keys []string, namespace string) (string, []string, error) {
+	var signers strings.Builder
+	var gpgKeys []string
+	specDir := filepath.Dir(activeScope.specPath())
+	for _, key := range keys {
+		if isSSHPublicKey(key) {
+			signers.WriteString(allowedSignerLine(key, namespace))
+			continue
+		}
+		path := key
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(specDir, filepath.FromSlash(key))
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", nil, err
+		}
+		if strings.Contains(string(data), "-----BEGIN PGP PUBLIC KEY BLOCK-----") {
+			gpgKeys = append(gpgKeys, path)
+			continue
+		}
+		scanner := bufio.NewScanner(strings.NewReader(string(data)))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			switch {
+			case line == "" || strings.HasPrefix(line, "#"):
+			case isSSHPublicKey(line):
+				signers.WriteString(allowedSignerLine(line, namespace))
+			default:
+				signers.WriteString(line + "\n")
+			}
+		}
+	}
+	return signers.String(), gpgKeys, nil
+}
+
+func (k *keyring) close() {
+	_ = os.RemoveAll(k.dir)
+}
+
+func (k *keyring) importGPG(path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "gpg", "--batch", "--quiet", "--import", path)
+	cmd.Env = append(os.Environ(), "GNUPGHOME="+k.gnupgHome)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("gpg --import failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// verify runs git verifyCmd ("verify-commit" or "verify-tag") on object
+// against the keyring. It returns the signer, or nil and what git reported.
+func (k *keyring) verify(dir, verifyCmd, object string) (*lock.Signer, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", verifyCmd, "--raw", object)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_TERMINAL_PROMPT=0",
+		"GNUPGHOME="+k.gnupgHome,
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=gpg.ssh.allowedSignersFile",
+		"GIT_CONFIG_VALUE_0="+k.allowedSigners,
+	)
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Sprintf("git %s timed out", verifyCmd)
+	}
+	detail := lastLine(string(out))
+	if detail == "" {
+		detail = "no signature"
+	}
+	if err != nil {
+		return nil, detail
+	}
+	if match := sshSignerPattern.FindStringSubmatch(string(out)); match != nil {
+		return &lock.Signer{Format: "ssh", Fingerprint: match[1]}, ""
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && fields[0] == "[GNUPG:]" && fields[1] == "VALIDSIG" {
+			// Prefer the primary key fingerprint; older GnuPG versions only
+			// report the signing key's.
+			fingerprint := fields[2]
+			if len(fields) >= 12 {
+				fingerprint = fields[11]
+			}
+			return &lock.Signer{Format: "gpg", Fingerprint: fingerprint}, ""
+		}
+	}
+	return nil, detail
+}
+
+func isSSHPublicKey(key string) bool {
+	for _, prefix := range sshKeyTypes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedSignerLine lets key sign for any principal in namespace.
+func allowedSignerLine(key, namespace string) string {
+	return fmt.Sprintf("* namespaces=%q %s\n", namespace, strings.TrimSpace(key))
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
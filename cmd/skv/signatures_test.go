@@ -0,0 +1,41 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/skill-vendor/skv/internal/spec"
+)
+
+func TestSignatureConfig(t *testing.T) {
+	global := &spec.Verify{Signatures: spec.SignaturesRequired, Keys: []string{"keys/org.pub"}}
+	cases := []struct {
+		name   string
+		skill  spec.SkillEntry
+		global *spec.Verify
+		want   *spec.Verify
+	}{
+		{"none", spec.SkillEntry{Repo: "r"}, nil, nil},
+		{"global", spec.SkillEntry{Repo: "r"}, global, global},
+		{"skill keys", spec.SkillEntry{Repo: "r", Verify: &spec.Verify{Keys: []string{"team.pub"}}}, global,
+			&spec.Verify{Signatures: spec.SignaturesRequired, Keys: []string{"team.pub"}}},
+		{"skill off", spec.SkillEntry{Repo: "r", Verify: &spec.Verify{Signatures: spec.SignaturesOff}}, global, nil},
+		{"skill only", spec.SkillEntry{Repo: "r", Verify: global}, nil, global},
+		{"local", spec.SkillEntry{Local: "./x"}, global, nil},
+	}
+	for _, c := range cases {
+		if got := signatureConfig(c.skill, c.global); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: signatureConfig = %+v, want %+v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestAllowedSignerLine(t *testing.T) {
+	got := allowedSignerLine("ssh-ed25519 AAAAC3Nza release\n", "git")
+	if want := "* namespaces=\"git\" ssh-ed25519 AAAAC3Nza release\n"; got != want {
+		t.Errorf("allowedSignerLine = %q, want %q", got, want)
+	}
+	if isSSHPublicKey("keys/release.pub") || !isSSHPublicKey("sk-ssh-ed25519@openssh.com AAAA") {
+		t.Error("isSSHPublicKey misclassified a key")
+	}
+}
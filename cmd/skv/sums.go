@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/skill-vendor/skv/internal/dirhash"
+	"github.com/skill-vendor/skv/internal/lock"
+	"github.com/skill-vendor/skv/internal/spec"
+	"github.com/skill-vendor/skv/internal/sumfile"
+)
+
+// sumPath is the checksum log kept next to the active lock file.
+func sumPath() string {
+	return filepath.Join(filepath.Dir(activeScope.lockPath()), "skv.sum")
+}
+
+// fetchedSum is the checksum of content fetched for a skill, to be recorded
+// in skv.sum once the skill is vendored.
+type fetchedSum struct {
+	key      sumfile.Key
+	checksum string
+}
+
+// sumModeNone labels checksums in skv.sum hashed byte for byte; the others are
+// labeled with their dirhash normalization mode. The label lets a line be
+// compared only with a hash made the same way.
+const sumModeNone = "none"
+
+// sumChecksum labels hash with the normalization mode it was made under.
+func sumChecksum(mode, hash string) string {
+	if mode == dirhash.NormalizeNone {
+		mode = sumModeNone
+	}
+	return mode + ":" + hash
+}
+
+// sumMode returns the normalization mode a recorded checksum was made under.
+func sumMode(checksum string) (string, bool) {
+	label, _, _ := strings.Cut(checksum, ":")
+	if label == sumModeNone {
+		return dirhash.NormalizeNone, true
+	}
+	return label, label != dirhash.NormalizeNone && dirhash.ValidNormalize(label)
+}
+
+// checkFetchedSum hashes the content at srcPath, fetched for skill at commit,
+// and fails if skv.sum recorded a different checksum for it. The hash covers
+// every file in the skill directory before filters and patches, so it depends
+// only on the commit and path. It uses the mode of the recorded line, or else
+// hashOpts' mode, so content is compared byte for byte unless the lock
+// normalizes line endings.
+func checkFetchedSum(skill spec.SkillEntry, commit, srcPath string, hashOpts dirhash.Options) (fetchedSum, error) {
+	sums, err := sumfile.Load(sumPath())
+	if err != nil {
+		return fetchedSum{}, fmt.Errorf("%w; resolve the conflicting lines in skv.sum before fetching", err)
+	}
+	key := sumfile.Key{Repo: skill.Repo, Commit: commit, Path: filepath.ToSlash(skill.Path)}
+	mode := hashOpts.Normalize
+	if recorded, ok := sums.Lookup(key); ok {
+		if mode, ok = sumMode(recorded); !ok {
+			return fetchedSum{}, fmt.Errorf("skv.sum: %s has checksum %q without a known mode (none: or text:)", key, recorded)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), hashTimeout)
+	defer cancel()
+	hash, err := dirhash.HashDirWithOptions(ctx, srcPath, dirhash.Options{Normalize: mode})
+	if err != nil {
+		return fetchedSum{}, err
+	}
+	checksum := sumChecksum(mode, hash)
+	if err := sums.Check(key, checksum); err != nil {
+		return fetchedSum{}, fmt.Errorf("%s: content of commit %s changed since it was first fetched: %w; "+
+			"its history may have been rewritten or the remote compromised, so it was not used", skill.Name, lock.ShortCommit(commit), err)
+	}
+	return fetchedSum{key: key, checksum: checksum}, nil
+}
+
+// cloneLockedCommit clones skill's repo at a commit recorded in skv.lock,
+// checked out sparsely at sparsePath if it is set, and checks the skill's
+// content there against skv.sum, so commands that rebuild from a locked
+// commit trust the remote no more than sync does. Nothing is recorded, so
+// only a recorded line's mode matters.
+func cloneLockedCommit(skill spec.SkillEntry, commit, sparsePath string) (string, error) {
+	cloneDir, err := cloneRepo(skill.Repo, commit, sparsePath)
+	if err != nil {
+		return "", err
+	}
+	if _, err := checkFetchedSum(skill, commit, filepath.Join(cloneDir, skill.Path), dirhash.Options{}); err != nil {
+		_ = os.RemoveAll(cloneDir)
+		return "", err
+	}
+	return cloneDir, nil
+}
+
+// record appends s to skv.sum unless it is already there.
+func (s fetchedSum) record() error {
+	path := sumPath()
+	sums, err := sumfile.Load(path)
+	if err != nil {
+		return err
+	}
+	if _, ok := sums.Lookup(s.key); ok {
+		return nil
+	}
+	return sumfile.Append(path, s.key, s.checksum)
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/skill-vendor/skv/internal/lock"
+	"github.com/skill-vendor/skv/internal/spec"
+)
+
+type tidyOptions struct {
+	dryRun bool
+	json   bool
+}
+
+// Kinds of drift reported by tidy.
+const (
+	tidyOrphanLock   = "orphan-lock"
+	tidyOrphanVendor = "orphan-vendor"
+	tidyStaleLink    = "stale-link"
+	tidyDanglingLink = "dangling-link"
+)
+
+type tidyFinding struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Detail string `json:"detail"`
+}
+
+func runTidy(opts tidyOptions) error {
+	specData, err := loadSpec()
+	if err != nil {
+		return err
+	}
+	lockData, _, err := loadLockOptional(activeScope.lockPath())
+	if err != nil {
+		return err
+	}
+
+	repoRoot, err := activeScope.root()
+	if err != nil {
+		return err
+	}
+
+	tools, err := resolveTools(specData)
+	if err != nil {
+		return err
+	}
+
+	findings, err := findTidyDrift(repoRoot, specData, lockData, tools)
+	if err != nil {
+		return err
+	}
+
+	if !opts.dryRun && len(findings) > 0 {
+		if err := applyTidy(repoRoot, lockData, findings); err != nil {
+			return err
+		}
+	}
+
+	if opts.json {
+		if findings == nil {
+			findings = []tidyFinding{}
+		}
+		data, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(findings) == 0 {
+		globalOutput.Info("Nothing to tidy")
+		return nil
+	}
+	verb := "Removed"
+	if opts.dryRun {
+		verb = "Would remove"
+	}
+	for _, finding := range findings {
+		globalOutput.Print("%s %s (%s)", verb, finding.subject(), finding.Detail)
+	}
+	if opts.dryRun {
+		globalOutput.Info("Found %d item(s) to tidy; re-run without --dry-run to apply", len(findings))
+		return nil
+	}
+	globalOutput.Success("Tidied %d item(s)", len(findings))
+	return nil
+}
+
+// findTidyDrift reports lock entries, vendored directories and tool links that
+// no spec entry accounts for. Only symlinks that resolve into .skv/skills and
+// copies carrying a link marker are considered managed; anything else in a
+// tool directory is left alone.
+func findTidyDrift(repoRoot string, specData *spec.Spec, lockData *lock.Lock, tools []agentTool) ([]tidyFinding, error) {
+	var findings []tidyFinding
+
+	wanted := make(map[string]spec.SkillEntry, len(specData.Skills))
+	for _, skill := range specData.Skills {
+		wanted[skill.Name] = skill
+	}
+
+	for _, entry := range lockData.Skills {
+		if _, ok := wanted[entry.Name]; ok {
+			continue
+		}
+		findings = append(findings, tidyFinding{
+			Kind:   tidyOrphanLock,
+			Name:   entry.Name,
+			Path:   "skv.lock",
+			Detail: "not in skv.cue",
+		})
+	}
+
+	vendorRoot := activeScope.vendorRoot(repoRoot)
+	referenced := make(map[string]struct{}, len(wanted))
+	for name := range wanted {
+		referenced[name] = struct{}{}
+	}
+	for _, skill := range specData.Skills {
+		if skill.Local == "" {
+			continue
+		}
+		// A local entry may point at a source directory inside .skv/skills.
+		if dir, ok := vendorChild(repoRoot, vendorRoot, skill.Local); ok {
+			referenced[dir] = struct{}{}
+		}
+	}
+
+	entries, err := os.ReadDir(vendorRoot)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, ok := referenced[entry.Name()]; ok {
+			continue
+		}
+		findings = append(findings, tidyFinding{
+			Kind:   tidyOrphanVendor,
+			Name:   entry.Name(),
+			Path:   relPath(repoRoot, filepath.Join(vendorRoot, entry.Name())),
+			Detail: "vendored directory not referenced by skv.cue",
+		})
+	}
+
+	for _, tool := range tools {
+		dir := filepath.Join(repoRoot, tool.Dir)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		for _, entry := range entries {
+			linkPath := filepath.Join(dir, entry.Name())
+			info, err := os.Lstat(linkPath)
+			if err != nil {
+				return nil, err
+			}
+			// target is the skill the symlink or skv-managed copy presents.
+			var target string
+			copied := false
+			switch {
+			case info.Mode()&os.ModeSymlink != 0:
+				dest, err := os.Readlink(linkPath)
+				if err != nil {
+					return nil, err
+				}
+				var managed bool
+				target, managed = vendorChild(filepath.Dir(linkPath), vendorRoot, dest)
+				if !managed {
+					continue
+				}
+			case info.IsDir():
+				marker, err := readLinkMarker(linkPath)
+				if err != nil {
+					return nil, err
+				}
+				if marker == nil {
+					continue
+				}
+				target, copied = marker.Skill, true
+			default:
+				continue
+			}
+
+			finding := tidyFinding{Name: entry.Name(), Path: relPath(repoRoot, linkPath)}
+			skill, isWanted := wanted[entry.Name()]
+			switch {
+			case !copied && !exists(filepath.Join(vendorRoot, target)):
+				finding.Kind = tidyDanglingLink
+				finding.Detail = fmt.Sprintf("link target %s does not exist", relPath(repoRoot, filepath.Join(vendorRoot, target)))
+			case !isWanted:
+				finding.Kind = tidyStaleLink
+				finding.Detail = fmt.Sprintf("skill %q is not in skv.cue", entry.Name())
+			case tool.Excluded:
+				finding.Kind = tidyStaleLink
+				finding.Detail = fmt.Sprintf("tool %q is excluded", tool.Name)
+			case !linksTool(skill, tool):
+				finding.Kind = tidyStaleLink
+				finding.Detail = fmt.Sprintf("skill %q does not target tool %q", skill.Name, tool.Name)
+			case target != entry.Name() && copied:
+				finding.Kind = tidyStaleLink
+				finding.Detail = fmt.Sprintf("copy of skill %q", target)
+			case target != entry.Name():
+				finding.Kind = tidyStaleLink
+				finding.Detail = fmt.Sprintf("link points at %s", relPath(repoRoot, filepath.Join(vendorRoot, target)))
+			default:
+				continue
+			}
+			findings = append(findings, finding)
+		}
+	}
+
+	return findings, nil
+}
+
+func (f tidyFinding) subject() string {
+	if f.Kind == tidyOrphanLock {
+		return fmt.Sprintf("%s entry %q", f.Path, f.Name)
+	}
+	return f.Path
+}
+
+func applyTidy(repoRoot string, lockData *lock.Lock, findings []tidyFinding) error {
+	orphaned := make(map[string]struct{})
+	for _, finding := range findings {
+		switch finding.Kind {
+		case tidyOrphanLock:
+			orphaned[finding.Name] = struct{}{}
+		case tidyOrphanVendor:
+			if err := os.RemoveAll(filepath.Join(repoRoot, filepath.FromSlash(finding.Path))); err != nil {
+				return fmt.Errorf("failed to remove vendor directory: %w", err)
+			}
+		case tidyStaleLink, tidyDanglingLink:
+			if err := removeToolLink(filepath.Join(repoRoot, filepath.FromSlash(finding.Path))); err != nil {
+				return fmt.Errorf("failed to remove link %s: %w", finding.Path, err)
+			}
+		}
+	}
+	if len(orphaned) == 0 {
+		return nil
+	}
+
+	var lockSkills []lock.Skill
+	for _, entry := range lockData.Skills {
+		if _, drop := orphaned[entry.Name]; !drop {
+			lockSkills = append(lockSkills, entry)
+		}
+	}
+	lockData.Skills = lockSkills
+	return lock.Write(activeScope.lockPath(), lockData)
+}
+
+// vendorChild resolves path relative to base and reports the name of the
+// .skv/skills entry it points at, if it points directly at one.
+func vendorChild(base, vendorRoot, path string) (string, bool) {
+	abs := path
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(base, path)
+	}
+	rel, err := filepath.Rel(vendorRoot, filepath.Clean(abs))
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return "", false
+	}
+	if strings.ContainsRune(rel, os.PathSeparator) {
+		return "", false
+	}
+	return rel, true
+}
+
+func relPath(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
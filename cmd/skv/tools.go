@@ -0,0 +1,313 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/skill-vendor/skv/internal/dirhash"
+	"github.com/skill-vendor/skv/internal/fsutil"
+	"github.com/skill-vendor/skv/internal/lock"
+	"github.com/skill-vendor/skv/internal/spec"
+)
+
+// Link modes for tool directories.
+const (
+	linkSymlink  = "symlink"
+	linkCopy     = "copy"
+	linkHardlink = "hardlink"
+)
+
+// linkMarker is written into copied and hard-linked installs so skv can tell
+// them apart from directories the user owns.
+const linkMarker = ".skv-link.json"
+
+type linkMarkerData struct {
+	Skill    string `json:"skill"`
+	Link     string `json:"link"`
+	Checksum string `json:"checksum"` // lock checksum of the content that was installed
+}
+
+// agentTool is an agent that loads skills from a directory in the repo.
+type agentTool struct {
+	Name     string
+	Dir      string // relative to the repo root
+	Link     string
+	Excluded bool // listed in tools.exclude; existing links are still cleaned up
+}
+
+// builtinTools are registered without any configuration in skv.cue.
+var builtinTools = []agentTool{
+	{Name: "claude", Dir: filepath.Join(".claude", "skills"), Link: linkSymlink},
+	{Name: "codex", Dir: filepath.Join(".codex", "skills"), Link: linkSymlink},
+	{Name: "opencode", Dir: filepath.Join(".opencode", "skill"), Link: linkSymlink},
+}
+
+// resolveTools builds the tool registry for specData: the built-ins, extended
+// or overridden by tools.define, sorted by name.
+func resolveTools(specData *spec.Spec) ([]agentTool, error) {
+	byName := make(map[string]agentTool, len(builtinTools))
+	for _, tool := range builtinTools {
+		byName[tool.Name] = tool
+	}
+
+	if specData.Tools != nil {
+		defined := make(map[string]struct{}, len(specData.Tools.Define))
+		for _, def := range specData.Tools.Define {
+			name := strings.ToLower(def.Name)
+			if name == "" {
+				return nil, fmt.Errorf("tools.define: tool missing name")
+			}
+			if _, dup := defined[name]; dup {
+				return nil, fmt.Errorf("tools.define: duplicate tool %q", name)
+			}
+			defined[name] = struct{}{}
+
+			dir, err := cleanToolDir(def.Dir)
+			if err != nil {
+				return nil, fmt.Errorf("tools.define: tool %q: %w", name, err)
+			}
+			link := def.Link
+			if link == "" {
+				link = linkSymlink
+			}
+			if link != linkSymlink && link != linkCopy && link != linkHardlink {
+				return nil, fmt.Errorf("tools.define: tool %q: unknown link mode %q", name, link)
+			}
+			byName[name] = agentTool{Name: name, Dir: dir, Link: link}
+		}
+	}
+
+	excluded := buildExcluded(specData)
+	tools := make([]agentTool, 0, len(byName))
+	for _, tool := range byName {
+		_, tool.Excluded = excluded[tool.Name]
+		tools = append(tools, tool)
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+
+	dirs := make(map[string]string, len(tools))
+	for _, tool := range tools {
+		if other, ok := dirs[tool.Dir]; ok {
+			return nil, fmt.Errorf("tools %q and %q both link into %s", other, tool.Name, filepath.ToSlash(tool.Dir))
+		}
+		dirs[tool.Dir] = tool.Name
+	}
+
+	for _, skill := range specData.Skills {
+		if len(skill.Tools) > 0 && len(skill.ExcludeTools) > 0 {
+			return nil, fmt.Errorf("skill %q: set tools or excludeTools, not both", skill.Name)
+		}
+		for _, name := range append(slices.Clone(skill.Tools), skill.ExcludeTools...) {
+			if _, ok := byName[strings.ToLower(name)]; !ok {
+				return nil, fmt.Errorf("skill %q: unknown tool %q", skill.Name, name)
+			}
+		}
+	}
+	return tools, nil
+}
+
+// linksTool reports whether skill should be linked into tool's directory,
+// honoring tools.exclude and the skill's own tools/excludeTools.
+func linksTool(skill spec.SkillEntry, tool agentTool) bool {
+	if tool.Excluded {
+		return false
+	}
+	if len(skill.Tools) > 0 {
+		return containsTool(skill.Tools, tool.Name)
+	}
+	return !containsTool(skill.ExcludeTools, tool.Name)
+}
+
+func containsTool(names []string, name string) bool {
+	return slices.ContainsFunc(names, func(n string) bool { return strings.EqualFold(n, name) })
+}
+
+// cleanToolDir validates a tool directory from skv.cue and returns it in OS form.
+func cleanToolDir(dir string) (string, error) {
+	if dir == "" {
+		return "", fmt.Errorf("dir is required")
+	}
+	if filepath.IsAbs(dir) || strings.HasPrefix(dir, "/") {
+		return "", fmt.Errorf("dir %q must be relative to the repo root", dir)
+	}
+	cleaned := filepath.Clean(filepath.FromSlash(dir))
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(os.PathSeparator)) {
+		return "", fmt.Errorf("dir %q must be inside the repo", dir)
+	}
+	first := strings.SplitN(cleaned, string(os.PathSeparator), 2)[0]
+	if first == ".skv" || first == ".git" {
+		return "", fmt.Errorf("dir %q must not be inside %s", dir, first)
+	}
+	return cleaned, nil
+}
+
+// installLink presents the vendored skill at target under linkPath using the
+// tool's link mode, replacing an earlier skv-managed install if needed.
+func installLink(tool agentTool, target, linkPath string, entry lock.Skill, hashOpts dirhash.Options) error {
+	if tool.Link == linkSymlink {
+		return ensureLink(target, linkPath)
+	}
+
+	if info, err := os.Lstat(linkPath); err == nil {
+		if info.IsDir() {
+			marker, err := readLinkMarker(linkPath)
+			if err != nil {
+				return err
+			}
+			if marker == nil {
+				return fmt.Errorf("refusing to replace directory %s", linkPath)
+			}
+			if checkCopy(linkPath, tool.Link, entry.Checksum, hashOpts) == nil {
+				return nil
+			}
+			if err := os.RemoveAll(linkPath); err != nil {
+				return err
+			}
+		} else if err := os.Remove(linkPath); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err := fsutil.EnsureDir(filepath.Dir(linkPath)); err != nil {
+		return err
+	}
+	place := fsutil.CopyDir
+	if tool.Link == linkHardlink {
+		place = fsutil.LinkDir
+	}
+	if err := place(target, linkPath, nil); err != nil {
+		_ = os.RemoveAll(linkPath)
+		if tool.Link == linkHardlink {
+			return fmt.Errorf("failed to hard-link %s (use link: %q across filesystems): %w", linkPath, linkCopy, err)
+		}
+		return fmt.Errorf("failed to copy skill to %s: %w", linkPath, err)
+	}
+	data, err := json.MarshalIndent(linkMarkerData{Skill: entry.Name, Link: tool.Link, Checksum: entry.Checksum}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(linkPath, linkMarker), append(data, '\n'), 0o644)
+}
+
+// checkCopy reports whether linkPath is an up-to-date, unmodified skv-managed
+// install of content with the given lock checksum.
+func checkCopy(linkPath, mode, checksum string, hashOpts dirhash.Options) error {
+	info, err := os.Lstat(linkPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%s is missing", mode)
+		}
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("not a directory (want %s)", mode)
+	}
+	marker, err := readLinkMarker(linkPath)
+	if err != nil {
+		return err
+	}
+	if marker == nil {
+		return fmt.Errorf("directory is not managed by skv")
+	}
+	if marker.Link != mode {
+		return fmt.Errorf("installed as %s, want %s", marker.Link, mode)
+	}
+	if marker.Checksum != checksum {
+		return fmt.Errorf("%s is stale (installed %s, locked %s)", mode, marker.Checksum, checksum)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hashTimeout)
+	defer cancel()
+	files, err := dirhash.Files(ctx, linkPath, hashOpts)
+	if err != nil {
+		return err
+	}
+	files = slices.DeleteFunc(files, func(file dirhash.File) bool { return file.Path == linkMarker })
+	if dirhash.Sum(files) != checksum {
+		return fmt.Errorf("%s has local changes", mode)
+	}
+	return nil
+}
+
+// readLinkMarker returns the marker of an skv-managed install in dir, or nil
+// if dir has none.
+func readLinkMarker(dir string) (*linkMarkerData, error) {
+	data, err := os.ReadFile(filepath.Join(dir, linkMarker))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var marker linkMarkerData
+	if err := json.Unmarshal(data, &marker); err != nil {
+		return nil, fmt.Errorf("invalid %s in %s: %w", linkMarker, dir, err)
+	}
+	return &marker, nil
+}
+
+// removeToolLink removes a symlink or skv-managed install at linkPath.
+func removeToolLink(linkPath string) error {
+	info, err := os.Lstat(linkPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if !info.IsDir() {
+		return os.Remove(linkPath)
+	}
+	marker, err := readLinkMarker(linkPath)
+	if err != nil {
+		return err
+	}
+	if marker == nil {
+		return fmt.Errorf("refusing to remove directory %s: not managed by skv", linkPath)
+	}
+	return os.RemoveAll(linkPath)
+}
+
+// removeManagedLink removes linkPath if it is a symlink into the vendored copy
+// of name or an skv-managed install of it. Anything else is left alone.
+// It reports whether a link was removed.
+func removeManagedLink(repoRoot, name, linkPath string) (bool, error) {
+	info, err := os.Lstat(linkPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		dest, err := os.Readlink(linkPath)
+		if err != nil {
+			return false, err
+		}
+		target, ok := vendorChild(filepath.Dir(linkPath), activeScope.vendorRoot(repoRoot), dest)
+		if !ok || target != name {
+			return false, nil
+		}
+	case info.IsDir():
+		marker, err := readLinkMarker(linkPath)
+		if err != nil {
+			return false, err
+		}
+		if marker == nil || marker.Skill != name {
+			return false, nil
+		}
+	default:
+		return false, nil
+	}
+	return true, os.RemoveAll(linkPath)
+}
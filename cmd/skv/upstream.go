@@ -0,0 +1,437 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/skill-vendor/skv/internal/dirhash"
+	"github.com/skill-vendor/skv/internal/lock"
+	"github.com/skill-vendor/skv/internal/spec"
+)
+
+// maxUpstreamCommits bounds how much of an upstream repo's history is searched.
+const maxUpstreamCommits = 1000
+
+// upstreamResult describes where local skill content was found upstream. When
+// exact is false, commit and path locate the closest content and diffs and
+// patch describe how the local copy differs from it.
+type upstreamResult struct {
+	exact  bool
+	commit string
+	path   string
+	diffs  []fileDiff
+	patch  string
+}
+
+// upstreamTree is a directory in one commit of the upstream history.
+type upstreamTree struct {
+	commit string
+	path   string
+	tree   string
+}
+
+// findUpstream searches the history of repo at ref (the default branch if
+// empty) for a commit whose subtree equals the content of localDir. If subpath
+// is set only that directory is considered; otherwise any directory matches
+// exactly and directories holding a SKILL.md are candidates for a near-miss.
+// It returns nil if nothing in the history resembles the local content.
+func findUpstream(localDir, repo, ref, subpath string, hashOpts dirhash.Options) (*upstreamResult, error) {
+	cloneDir, err := cloneRepo(repo, ref, "")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(cloneDir)
+
+	localTree, localBlobs, err := writeLocalTree(cloneDir, localDir, hashOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := runGitCommandOutput(cloneDir, "rev-list", fmt.Sprintf("--max-count=%d", maxUpstreamCommits), "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	commits := strings.Fields(string(out))
+	if len(commits) == maxUpstreamCommits {
+		globalOutput.Info("Searching only the latest %d commits of %s", maxUpstreamCommits, repo)
+	}
+
+	candidates, err := skillDirs(cloneDir, subpath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk newest to oldest, remembering each distinct candidate tree for the
+	// near-miss comparison should no commit match exactly.
+	var near []upstreamTree
+	seen := make(map[string]bool)
+	for _, commit := range commits {
+		trees, err := commitTrees(cloneDir, commit)
+		if err != nil {
+			return nil, err
+		}
+		if subpath != "" {
+			if trees[subpath] == localTree {
+				return &upstreamResult{exact: true, commit: commit, path: subpath}, nil
+			}
+		} else if match, ok := matchingPath(trees, localTree); ok {
+			return &upstreamResult{exact: true, commit: commit, path: match}, nil
+		}
+		for _, dir := range candidates {
+			tree, ok := trees[dir]
+			if !ok || seen[tree] {
+				continue
+			}
+			seen[tree] = true
+			near = append(near, upstreamTree{commit: commit, path: dir, tree: tree})
+		}
+	}
+
+	var best *upstreamResult
+	var bestTree string
+	for _, candidate := range near {
+		blobs, err := treeBlobs(cloneDir, candidate.tree)
+		if err != nil {
+			return nil, err
+		}
+		if !sharesPath(blobs, localBlobs) {
+			continue
+		}
+		diffs := diffFileSums(blobs, localBlobs)
+		if best == nil || len(diffs) < len(best.diffs) {
+			best = &upstreamResult{commit: candidate.commit, path: candidate.path, diffs: diffs}
+			bestTree = candidate.tree
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+	if best.patch, err = diffTrees(cloneDir, bestTree, localTree); err != nil {
+		return nil, err
+	}
+	return best, nil
+}
+
+// diffTrees returns a patch from the upstream tree to the local tree.
+func diffTrees(cloneDir, upstreamTree, localTree string) (string, error) {
+	out, err := runGitCommandOutput(cloneDir, "diff", "--no-color", "--no-ext-diff",
+		"--src-prefix=upstream/", "--dst-prefix=local/", upstreamTree, localTree)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// commitPathTree returns the tree id of subpath in commit; "" is the root.
+func commitPathTree(cloneDir, commit, subpath string) (string, error) {
+	rev := commit + "^{tree}"
+	if subpath != "" {
+		rev = commit + ":" + filepath.ToSlash(subpath)
+	}
+	out, err := runGitCommandOutput(cloneDir, "rev-parse", rev)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// writeLocalTree stores the content of dir as a tree in the clone's object
+// database using a scratch index, and returns the tree id and the blob id of
+// each file. Under text normalization CRLF line endings are stored as LF, as
+// they would be when committed.
+func writeLocalTree(cloneDir, dir string, hashOpts dirhash.Options) (string, map[string]string, error) {
+	gitDir := filepath.Join(cloneDir, ".git")
+	index := filepath.Join(gitDir, "skv-upstream-index")
+	if err := os.Remove(index); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", nil, err
+	}
+	env := []string{"GIT_INDEX_FILE=" + index}
+	autocrlf := "false"
+	if hashOpts.Normalize == dirhash.NormalizeText {
+		autocrlf = "input"
+	}
+	base := []string{"--git-dir=" + gitDir, "--work-tree=" + dir, "-c", "core.autocrlf=" + autocrlf}
+
+	if _, err := runGitCommandEnv(dir, env, append(base, "add", "--all", "--force", ".")...); err != nil {
+		return "", nil, err
+	}
+	out, err := runGitCommandEnv(dir, env, append(base, "write-tree")...)
+	if err != nil {
+		return "", nil, err
+	}
+	tree := strings.TrimSpace(string(out))
+	blobs, err := treeBlobs(cloneDir, tree)
+	if err != nil {
+		return "", nil, err
+	}
+	return tree, blobs, nil
+}
+
+// commitTrees maps every directory in commit to its tree id; the root is "".
+func commitTrees(cloneDir, commit string) (map[string]string, error) {
+	root, err := commitPathTree(cloneDir, commit, "")
+	if err != nil {
+		return nil, err
+	}
+	trees := map[string]string{"": root}
+	out, err := runGitCommandOutput(cloneDir, "ls-tree", "-r", "-d", "-z", commit)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(out), "\x00") {
+		meta, name, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		if fields := strings.Fields(meta); len(fields) == 3 {
+			trees[name] = fields[2]
+		}
+	}
+	return trees, nil
+}
+
+// treeBlobs maps each file path under tree to its mode and blob id.
+func treeBlobs(cloneDir, tree string) (map[string]string, error) {
+	out, err := runGitCommandOutput(cloneDir, "ls-tree", "-r", "-z", tree)
+	if err != nil {
+		return nil, err
+	}
+	blobs := make(map[string]string)
+	for _, line := range strings.Split(string(out), "\x00") {
+		meta, name, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		if fields := strings.Fields(meta); len(fields) == 3 {
+			blobs[name] = fields[0] + " " + fields[2]
+		}
+	}
+	return blobs, nil
+}
+
+// skillDirs returns the directories compared for a near-miss: subpath if set,
+// otherwise every directory holding a SKILL.md at the checked-out ref.
+func skillDirs(cloneDir, subpath string) ([]string, error) {
+	if subpath != "" {
+		return []string{subpath}, nil
+	}
+	out, err := runGitCommandOutput(cloneDir, "ls-tree", "-r", "-z", "--name-only", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, name := range strings.Split(string(out), "\x00") {
+		if path.Base(name) != "SKILL.md" {
+			continue
+		}
+		dir := path.Dir(name)
+		if dir == "." {
+			dir = ""
+		}
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// matchingPath returns the shallowest directory whose tree is tree.
+func matchingPath(trees map[string]string, tree string) (string, bool) {
+	var matches []string
+	for dir, id := range trees {
+		if id == tree {
+			matches = append(matches, dir)
+		}
+	}
+	if len(matches) == 0 {
+		return "", false
+	}
+	depth := func(dir string) int {
+		if dir == "" {
+			return -1
+		}
+		return strings.Count(dir, "/")
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if di, dj := depth(matches[i]), depth(matches[j]); di != dj {
+			return di < dj
+		}
+		return matches[i] < matches[j]
+	})
+	return matches[0], true
+}
+
+// sharesPath reports whether any file path appears in both a and b.
+func sharesPath(a, b map[string]string) bool {
+	for key := range a {
+		if _, ok := b[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// reportUpstreamMiss explains why no upstream commit was linked, printing the
+// closest candidate's diff when there is one.
+func reportUpstreamMiss(name, repo string, result *upstreamResult) error {
+	if result == nil {
+		return fmt.Errorf("no content in %s resembles %s", repo, name)
+	}
+	location := result.path
+	if location == "" {
+		location = "repo root"
+	}
+	globalOutput.Print("Closest match for %s: %s at %s (%d file(s) differ)", name, location, lock.ShortCommit(result.commit), len(result.diffs))
+	for _, diff := range result.diffs {
+		globalOutput.Print("  %-8s %s", diff.Change, diff.Path)
+	}
+	if result.patch != "" {
+		globalOutput.Print("%s", strings.TrimRight(result.patch, "\n"))
+	}
+	return fmt.Errorf("no commit in %s matches %s exactly", repo, name)
+}
+
+// runLinkUpstream converts a local skill into a remote pinned to the upstream
+// commit its content was copied from.
+func runLinkUpstream(name, repoArg string) error {
+	repoRoot, err := activeScope.root()
+	if err != nil {
+		return err
+	}
+	specData, err := loadSpec()
+	if err != nil {
+		return err
+	}
+	skill, ok := findSkill(specData, name)
+	if !ok {
+		return fmt.Errorf("skill %q not found in spec", name)
+	}
+	if skill.Local == "" {
+		return fmt.Errorf("skill %q is already a remote skill", name)
+	}
+	localDir, err := resolveLocalPath(repoRoot, skill.Local)
+	if err != nil {
+		return err
+	}
+
+	repo, ref, subpath, err := parseUpstreamArg(repoArg)
+	if err != nil {
+		return err
+	}
+	globalOutput.Info("Searching %s for %s...", repo, name)
+	result, err := findUpstream(localDir, repo, ref, subpath, checksumOptions(specData))
+	if err != nil {
+		return err
+	}
+	if result == nil || !result.exact {
+		return reportUpstreamMiss(name, repo, result)
+	}
+	return pinToUpstream(repoRoot, name, repo, result)
+}
+
+// parseUpstreamArg splits <repo>[#ref][:path] and cleans the path.
+func parseUpstreamArg(repoArg string) (repo, ref, subpath string, err error) {
+	repo, ref, subpath = parseRepoArg(repoArg)
+	if repo == "" {
+		return "", "", "", fmt.Errorf("invalid repo argument")
+	}
+	subpath, err = cleanSubpath(subpath)
+	if err != nil {
+		return "", "", "", err
+	}
+	return repo, ref, filepath.ToSlash(subpath), nil
+}
+
+// pinToUpstream rewrites the local skill name as a remote entry pinned to the
+// matched commit, vendors it from upstream, and relinks it.
+func pinToUpstream(repoRoot, name, repo string, result *upstreamResult) error {
+	specData, err := spec.Load(activeScope.specPath())
+	if err != nil {
+		return err
+	}
+	index := -1
+	for i, skill := range specData.Skills {
+		if specData.QualifiedName(skill) == name {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return fmt.Errorf("skill %q not found in spec", name)
+	}
+	previous := specData.Skills[index]
+	skill := previous
+	skill.Local = ""
+	skill.Repo = repo
+	skill.Ref = result.commit
+	skill.Path = filepath.FromSlash(result.path)
+	if qualified := specData.QualifiedName(skill); qualified != name {
+		return fmt.Errorf("linking %q to %s would rename it to %q; set its namespace explicitly first", name, repo, qualified)
+	}
+
+	lockData, lockMap, err := loadLockOptional(activeScope.lockPath())
+	if err != nil {
+		return err
+	}
+	if err := ensureChecksumMode(specData, lockData); err != nil {
+		return err
+	}
+	hashOpts := checksumOptions(specData)
+
+	vendored := skill
+	vendored.Name = name
+	vendorPath := activeScope.vendorPath(repoRoot, name)
+	policy, err := loadPolicy(specData)
+	if err != nil {
+		return err
+	}
+	if err := reportPolicy(sourceViolations(policy, vendored)); err != nil {
+		return err
+	}
+	entry, err := fetchAndVendorRemote(repoRoot, vendored, vendorPath, syncOptions{hash: hashOpts, audit: specData.Audit, policy: policy, verify: specData.Verify})
+	if err != nil {
+		return err
+	}
+
+	specData.Skills[index] = skill
+	if err := spec.Write(activeScope.specPath(), specData); err != nil {
+		return err
+	}
+	lockMap[name] = entry
+	var lockSkills []lock.Skill
+	for _, s := range specData.Skills {
+		if e, ok := lockMap[specData.QualifiedName(s)]; ok {
+			lockSkills = append(lockSkills, e)
+		}
+	}
+	sort.Slice(lockSkills, func(i, j int) bool { return lockSkills[i].Name < lockSkills[j].Name })
+	lockData.Normalize = hashOpts.Normalize
+	lockData.Skills = lockSkills
+	if err := lock.Write(activeScope.lockPath(), lockData); err != nil {
+		return err
+	}
+
+	tools, err := resolveTools(specData)
+	if err != nil {
+		return err
+	}
+	if err := linkSkill(repoRoot, vendored, entry, tools, hashOpts); err != nil {
+		return err
+	}
+
+	if localDir, err := resolveLocalPath(repoRoot, previous.Local); err == nil {
+		if same, err := samePath(localDir, vendorPath); err == nil && !same {
+			globalOutput.Info("The local copy at %s is no longer used; remove it when ready", previous.Local)
+		}
+	}
+	location := result.path
+	if location == "" {
+		location = "repo root"
+	}
+	globalOutput.Success("Linked %s to %s (%s @ %s)", name, repo, location, lock.ShortCommit(result.commit))
+	return nil
+}
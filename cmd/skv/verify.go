@@ -0,0 +1,208 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/skill-vendor/skv/internal/dirhash"
+	"github.com/skill-vendor/skv/internal/lock"
+	"github.com/skill-vendor/skv/internal/spec"
+)
+
+// Categories of verify findings, one per pair of layers skv keeps in sync.
+const (
+	verifySpecLock      = "spec-lock"
+	verifyLockVendor    = "lock-vendor"
+	verifyVendorLink    = "vendor-link"
+	verifyPolicy        = "policy"
+	verifyNotices       = "notices"
+	verifyLockSignature = "lock-signature"
+)
+
+type verifyFinding struct {
+	Category string `json:"category"`
+	Skill    string `json:"skill,omitempty"`
+	Path     string `json:"path,omitempty"` // repo-relative file the finding is about
+	Message  string `json:"message"`
+}
+
+func (f verifyFinding) String() string {
+	if f.Skill == "" {
+		return fmt.Sprintf("%s: %s", f.Category, f.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", f.Category, f.Skill, f.Message)
+}
+
+// collectVerifyFindings checks spec against lock, lock against vendored
+// content (as inspected in reports), vendored content against tool links,
+// every locked skill against policy, and the generated notices against the
+// lock. It reports every problem it finds rather than stopping at the first
+// one.
+func collectVerifyFindings(repoRoot string, specData *spec.Spec, lockData *lock.Lock, tools []agentTool, reports []skillReport, policy *spec.Policy) []verifyFinding {
+	var findings []verifyFinding
+	add := func(category, skill, path, format string, args ...any) {
+		findings = append(findings, verifyFinding{Category: category, Skill: skill, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if want := checksumOptions(specData).Normalize; lockData.Normalize != want {
+		add(verifySpecLock, "", "skv.lock", "lock checksum mode %q does not match spec (%q)", lockData.Normalize, want)
+	}
+
+	lockMap := make(map[string]lock.Skill, len(lockData.Skills))
+	for _, entry := range lockData.Skills {
+		if entry.Name == "" {
+			add(verifySpecLock, "", "skv.lock", "lock entry missing name")
+			continue
+		}
+		if _, dup := lockMap[entry.Name]; dup {
+			add(verifySpecLock, entry.Name, "skv.lock", "duplicate lock entry")
+			continue
+		}
+		lockMap[entry.Name] = entry
+	}
+
+	specMap := make(map[string]spec.SkillEntry, len(specData.Skills))
+	for _, skill := range specData.Skills {
+		if skill.Name == "" {
+			add(verifySpecLock, "", "skv.cue", "skill missing name")
+			continue
+		}
+		if _, dup := specMap[skill.Name]; dup {
+			add(verifySpecLock, skill.Name, "skv.cue", "duplicate skill name in skv.cue")
+			continue
+		}
+		specMap[skill.Name] = skill
+
+		entry, ok := lockMap[skill.Name]
+		if !ok {
+			add(verifySpecLock, skill.Name, "skv.cue", "in skv.cue but not in skv.lock; run skv sync")
+			continue
+		}
+		if cleaned, err := cleanSubpath(skill.Path); err == nil {
+			skill.Path = cleaned
+		}
+		if !lockMatchesSpec(entry, skill) {
+			add(verifySpecLock, skill.Name, "skv.cue", "lock entry does not match skv.cue (%s); run skv sync", describeSpecDrift(entry, skill))
+			continue
+		}
+		if patches, err := resolvePatches(skill); err != nil {
+			add(verifySpecLock, skill.Name, "skv.cue", "%v", err)
+		} else if !samePatches(patches, entry.Patches) {
+			add(verifySpecLock, skill.Name, "skv.cue", "patches changed since skv.lock was written; run skv sync")
+		}
+		if f, err := skillFilter(skill, activeScope.vendorPath(repoRoot, skill.Name)); err != nil {
+			add(verifySpecLock, skill.Name, "skv.cue", "%v", err)
+		} else if !f.Equal(entry.Filter) {
+			add(verifySpecLock, skill.Name, "skv.cue", "file filter changed since skv.lock was written; run skv sync")
+		}
+		if entry.Signer == nil && signatureConfig(skill, specData.Verify) != nil {
+			add(verifySpecLock, skill.Name, "skv.lock", "signatures are required but skv.lock records no signer; run skv sync")
+		}
+	}
+	for _, entry := range lockData.Skills {
+		if _, ok := specMap[entry.Name]; !ok && entry.Name != "" {
+			add(verifySpecLock, entry.Name, "skv.lock", "in skv.lock but not in skv.cue; run skv tidy")
+		}
+	}
+
+	if !dirhash.ValidNormalize(lockData.Normalize) {
+		add(verifyLockVendor, "", "skv.lock", "lock has unknown checksum mode %q", lockData.Normalize)
+	} else {
+		for _, report := range reports {
+			if report.problem != "" {
+				add(verifyLockVendor, report.Name, relPath(repoRoot, activeScope.vendorPath(repoRoot, report.Name)), "%s", report.problem)
+			}
+		}
+	}
+
+	hashOpts := lockChecksumOptions(lockData)
+	for _, skill := range specData.Skills {
+		entry, ok := lockMap[skill.Name]
+		if !ok || skill.Name == "" {
+			continue
+		}
+		target := activeScope.vendorPath(repoRoot, skill.Name)
+		for _, tool := range tools {
+			if !linksTool(skill, tool) {
+				continue
+			}
+			linkPath := filepath.Join(repoRoot, tool.Dir, skill.Name)
+			var err error
+			if tool.Link == linkSymlink {
+				err = checkLink(target, linkPath)
+			} else {
+				err = checkCopy(linkPath, tool.Link, entry.Checksum, hashOpts)
+			}
+			if err != nil {
+				rel := relPath(repoRoot, linkPath)
+				add(verifyVendorLink, skill.Name, rel, "%s: %v", rel, err)
+			}
+		}
+	}
+
+	for _, skill := range specData.Skills {
+		entry, ok := lockMap[skill.Name]
+		if !ok || skill.Name == "" {
+			continue
+		}
+		for _, v := range sourceViolations(policy, skill) {
+			add(verifyPolicy, skill.Name, "skv.cue", "%s (%s)", v.Message, v.Rule)
+		}
+		vendorPath := activeScope.vendorPath(repoRoot, skill.Name)
+		if _, err := os.Stat(vendorPath); err != nil {
+			continue
+		}
+		violations, err := contentViolations(policy, skill, entry.License, vendorPath)
+		if err != nil {
+			add(verifyPolicy, skill.Name, relPath(repoRoot, vendorPath), "%v", err)
+			continue
+		}
+		for _, v := range violations {
+			add(verifyPolicy, skill.Name, relPath(repoRoot, filepath.Join(vendorPath, filepath.FromSlash(v.Path))), "%s (%s)", v.Message, v.Rule)
+		}
+	}
+
+	findings = append(findings, noticesFindings(repoRoot, specData, lockData)...)
+	return findings
+}
+
+// describeSpecDrift names the first field that differs between a lock entry and its spec entry.
+func describeSpecDrift(entry lock.Skill, skill spec.SkillEntry) string {
+	switch {
+	case entry.Local != skill.Local:
+		return fmt.Sprintf("local %q vs %q", entry.Local, skill.Local)
+	case entry.Repo != skill.Repo:
+		return fmt.Sprintf("repo %q vs %q", entry.Repo, skill.Repo)
+	case entry.Path != skill.Path:
+		return fmt.Sprintf("path %q vs %q", entry.Path, skill.Path)
+	default:
+		return fmt.Sprintf("ref %q vs %q", entry.Ref, skill.Ref)
+	}
+}
+
+// checkLink reports whether linkPath is a symlink that resolves to target.
+func checkLink(target, linkPath string) error {
+	info, err := os.Lstat(linkPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("link is missing")
+		}
+		return err
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return fmt.Errorf("not a symlink")
+	}
+	dest, err := os.Readlink(linkPath)
+	if err != nil {
+		return err
+	}
+	if !filepath.IsAbs(dest) {
+		dest = filepath.Join(filepath.Dir(linkPath), dest)
+	}
+	if filepath.Clean(dest) != filepath.Clean(target) {
+		return fmt.Errorf("link points at %s", dest)
+	}
+	return nil
+}
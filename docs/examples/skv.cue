@@ -3,6 +3,15 @@
 skv: {
 	tools: {
 		exclude: ["opencode"]
+		define: [
+			{name: "cursor", dir: ".cursor/skills"},
+		]
+	}
+	audit: {
+		block: "high"
+		allow: [
+			{skill: "release-notes", rule: "base64-blob", path: "assets/", reason: "embedded icons"},
+		]
 	}
 	skills: [
 		{
@@ -10,6 +19,7 @@ skv: {
 			repo: "https://github.com/acme/skill-pack"
 			path: "skills/release-notes"
 			ref:  "v1.2.3"
+			files: exclude: ["tests/"]
 		},
 		{
 			name:  "local-helper"
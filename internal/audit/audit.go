@@ -0,0 +1,245 @@
+// Package audit scans skill content for patterns that are risky to hand to an
+// agent with shell access: embedded secrets, piping downloads into a shell,
+// large encoded blobs, hidden Unicode, prompt-injection phrases, and
+// executable binaries.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/skill-vendor/skv/internal/dirhash"
+	"github.com/skill-vendor/skv/internal/filter"
+)
+
+// Rules reported by Scan.
+const (
+	RuleSecret           = "secret"
+	RulePipeToShell      = "pipe-to-shell"
+	RuleBase64Blob       = "base64-blob"
+	RuleHiddenUnicode    = "hidden-unicode"
+	RulePromptInjection  = "prompt-injection"
+	RuleExecutableBinary = "executable-binary"
+)
+
+// Severity ranks findings; a higher value is more severe.
+type Severity int
+
+const (
+	Low Severity = iota + 1
+	Medium
+	High
+)
+
+var severityNames = map[Severity]string{Low: "low", Medium: "medium", High: "high"}
+
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// MarshalText encodes s by name.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ParseSeverity returns the severity named name.
+func ParseSeverity(name string) (Severity, error) {
+	for s, n := range severityNames {
+		if n == name {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %q (want low, medium, or high)", name)
+}
+
+// Finding is one risky pattern in a file.
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path"` // slash-separated, relative to the scanned directory
+	Line     int      `json:"line,omitempty"`
+	Message  string   `json:"message"`
+}
+
+func (f Finding) String() string {
+	location := f.Path
+	if f.Line > 0 {
+		location = fmt.Sprintf("%s:%d", f.Path, f.Line)
+	}
+	return fmt.Sprintf("%s: [%s] %s (%s)", location, f.Severity, f.Message, f.Rule)
+}
+
+// minBase64Blob is the shortest run of base64 characters reported as a blob.
+const minBase64Blob = 200
+
+type pattern struct {
+	re      *regexp.Regexp
+	message string
+}
+
+var secretPatterns = []pattern{
+	{regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY(?: BLOCK)?-----`), "private key"},
+	{regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), "AWS access key ID"},
+	{regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`), "GitHub token"},
+	{regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{40,}\b`), "GitHub token"},
+	{regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}\b`), "GitLab token"},
+	{regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`), "Slack token"},
+	{regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{20,}`), "Anthropic API key"},
+	{regexp.MustCompile(`\bsk-(?:proj-)?[A-Za-z0-9]{32,}\b`), "OpenAI API key"},
+	{regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`), "Google API key"},
+	{regexp.MustCompile(`\b[sr]k_live_[0-9A-Za-z]{24,}\b`), "Stripe secret key"},
+}
+
+var pipeToShellPatterns = []pattern{
+	{regexp.MustCompile(`(?i)\b(?:curl|wget)\b[^|\n]*\|\s*(?:sudo\s+)?(?:ba|z|da|k)?sh\b`), "download piped into a shell"},
+	{regexp.MustCompile(`(?i)\b(?:curl|wget)\b[^|\n]*\|\s*(?:sudo\s+)?(?:python3?|node|perl|ruby)\b`), "download piped into an interpreter"},
+	{regexp.MustCompile(`(?i)\b(?:ba|z)?sh\s+<\(\s*(?:curl|wget)\b`), "download executed by a shell"},
+	{regexp.MustCompile(`(?i)\b(?:iex|invoke-expression)\b[^\n]*\b(?:iwr|irm|invoke-webrequest|invoke-restmethod|downloadstring)\b`), "download executed by PowerShell"},
+}
+
+var promptInjectionPatterns = []pattern{
+	{regexp.MustCompile(`(?i)\b(?:ignore|disregard|forget)\s+(?:all\s+|any\s+)?(?:the\s+)?(?:previous|prior|above|earlier|preceding)\s+(?:instructions|prompts|rules|directions)`), "asks the agent to ignore earlier instructions"},
+	{regexp.MustCompile(`(?i)\b(?:do\s+not|don't|never)\s+(?:tell|inform|notify|alert|mention\s+(?:this|it)\s+to)\s+the\s+user\b`), "asks the agent to hide actions from the user"},
+	{regexp.MustCompile(`(?i)\bwithout\s+(?:telling|informing|notifying|asking)\s+the\s+user\b`), "asks the agent to act without the user's knowledge"},
+	{regexp.MustCompile(`(?i)\byou\s+are\s+now\s+(?:in\s+)?(?:developer|god|jailbreak|DAN)\s+mode\b`), "attempts to switch the agent into an unrestricted mode"},
+	{regexp.MustCompile(`(?i)\b(?:reveal|print|output|send)\s+(?:your|the)\s+system\s+prompt\b`), "asks the agent to disclose its system prompt"},
+}
+
+var base64Blob = regexp.MustCompile(`[A-Za-z0-9+/]{` + fmt.Sprint(minBase64Blob) + `,}={0,2}`)
+
+// Scan checks every file under dir that f keeps (all if f is nil) and returns
+// the findings sorted by path and line.
+func Scan(dir string, f *filter.Filter) ([]Finding, error) {
+	var findings []Finding
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if d.Name() == ".git" || (rel != "." && f.SkipDir(rel)) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !f.Keep(rel) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		findings = append(findings, ScanFile(rel, data)...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Path != findings[j].Path {
+			return findings[i].Path < findings[j].Path
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	return findings, nil
+}
+
+// ScanFile checks the content of a single file named rel.
+func ScanFile(rel string, data []byte) []Finding {
+	if format := executableFormat(data); format != "" {
+		return []Finding{{Rule: RuleExecutableBinary, Severity: High, Path: rel, Message: format + " executable"}}
+	}
+	if dirhash.IsBinary(data) {
+		return nil
+	}
+
+	var findings []Finding
+	add := func(line int, rule string, severity Severity, message string) {
+		findings = append(findings, Finding{Rule: rule, Severity: severity, Path: rel, Line: line, Message: message})
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		for _, p := range secretPatterns {
+			if p.re.MatchString(line) {
+				add(n, RuleSecret, High, "possible "+p.message)
+			}
+		}
+		for _, p := range pipeToShellPatterns {
+			if p.re.MatchString(line) {
+				add(n, RulePipeToShell, High, p.message)
+				break
+			}
+		}
+		for _, p := range promptInjectionPatterns {
+			if p.re.MatchString(line) {
+				add(n, RulePromptInjection, Medium, p.message)
+			}
+		}
+		if blob := base64Blob.FindString(line); blob != "" {
+			add(n, RuleBase64Blob, Medium, fmt.Sprintf("%d-character base64 blob", len(blob)))
+		}
+		if severity, message := hiddenUnicode(line, n == 1); severity != 0 {
+			add(n, RuleHiddenUnicode, severity, message)
+		}
+	}
+	return findings
+}
+
+// hiddenUnicode reports the most severe invisible character in line. Bidi
+// controls can make text read differently than it runs, and tag characters
+// can smuggle instructions no reviewer sees; zero-width characters are less
+// often malicious. A byte order mark at the start of the file is allowed.
+func hiddenUnicode(line string, first bool) (Severity, string) {
+	var severity Severity
+	var message string
+	for i, r := range line {
+		switch {
+		case r >= 0x202A && r <= 0x202E, r >= 0x2066 && r <= 0x2069:
+			return High, fmt.Sprintf("bidirectional control character U+%04X", r)
+		case r >= 0xE0000 && r <= 0xE007F:
+			return High, fmt.Sprintf("invisible tag character U+%04X", r)
+		case r == 0x200B, r == 0x200C, r == 0x200D, r == 0x2060, r == 0xFEFF && !(first && i == 0):
+			if severity == 0 {
+				severity, message = Medium, fmt.Sprintf("zero-width character U+%04X", r)
+			}
+		}
+	}
+	return severity, message
+}
+
+// executableFormat names the executable format data starts with, or "".
+func executableFormat(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x7fELF")):
+		return "ELF"
+	case bytes.HasPrefix(data, []byte("MZ")) && dirhash.IsBinary(data):
+		return "Windows PE"
+	case bytes.HasPrefix(data, []byte{0xfe, 0xed, 0xfa, 0xce}), bytes.HasPrefix(data, []byte{0xfe, 0xed, 0xfa, 0xcf}),
+		bytes.HasPrefix(data, []byte{0xce, 0xfa, 0xed, 0xfe}), bytes.HasPrefix(data, []byte{0xcf, 0xfa, 0xed, 0xfe}),
+		bytes.HasPrefix(data, []byte{0xca, 0xfe, 0xba, 0xbe}):
+		return "Mach-O"
+	}
+	return ""
+}
+
+// HasPathPrefix reports whether rel is path or lies under the directory path.
+func HasPathPrefix(rel, path string) bool {
+	path = strings.TrimSuffix(path, "/")
+	return rel == path || strings.HasPrefix(rel, path+"/")
+}
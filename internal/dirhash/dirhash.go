@@ -1,6 +1,7 @@
 package dirhash
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"fmt"
@@ -9,8 +10,33 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+
+	"github.com/skill-vendor/skv/internal/filter"
+)
+
+// Normalization modes for file content.
+const (
+	// NormalizeNone hashes file content byte-for-byte.
+	NormalizeNone = ""
+	// NormalizeText hashes text files with CRLF line endings converted to LF.
+	NormalizeText = "text"
 )
 
+// sniffLen is how many leading bytes are inspected to decide whether a file is binary.
+const sniffLen = 8000
+
+// Options controls which files HashDirWithOptions reads and how.
+type Options struct {
+	Normalize string
+	// Filter, if set, limits hashing to the files it keeps.
+	Filter *filter.Filter
+}
+
+// ValidNormalize reports whether mode is a supported normalization mode.
+func ValidNormalize(mode string) bool {
+	return mode == NormalizeNone || mode == NormalizeText
+}
+
 // HashDir computes a deterministic hash based on the hashes of files in the directory.
 // It ignores .git directories and only considers regular files.
 func HashDir(root string) (string, error) {
@@ -19,12 +45,33 @@ func HashDir(root string) (string, error) {
 
 // HashDirWithContext is like HashDir but allows cancellation.
 func HashDirWithContext(ctx context.Context, root string) (string, error) {
-	type entry struct {
-		path string
-		mode fs.FileMode
+	return HashDirWithOptions(ctx, root, Options{})
+}
+
+// HashDirWithOptions is like HashDirWithContext but applies the given options.
+func HashDirWithOptions(ctx context.Context, root string, opts Options) (string, error) {
+	files, err := Files(ctx, root, opts)
+	if err != nil {
+		return "", err
+	}
+	return Sum(files), nil
+}
+
+// File is the digest of a single regular file within a hashed directory.
+type File struct {
+	Path string // slash-separated, relative to the root
+	Mode fs.FileMode
+	Sum  string // hex SHA-256 of the (possibly normalized) content
+}
+
+// Files walks root the same way HashDirWithOptions does and returns the
+// per-file digests sorted by path.
+func Files(ctx context.Context, root string, opts Options) ([]File, error) {
+	if !ValidNormalize(opts.Normalize) {
+		return nil, fmt.Errorf("unknown normalize mode %q", opts.Normalize)
 	}
-	var files []entry
 
+	var files []File
 	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
@@ -32,8 +79,13 @@ func HashDirWithContext(ctx context.Context, root string) (string, error) {
 		if err := ctx.Err(); err != nil {
 			return err
 		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
 		if d.IsDir() {
-			if d.Name() == ".git" {
+			if d.Name() == ".git" || (rel != "." && opts.Filter.SkipDir(rel)) {
 				return fs.SkipDir
 			}
 			return nil
@@ -42,37 +94,58 @@ func HashDirWithContext(ctx context.Context, root string) (string, error) {
 		if err != nil {
 			return err
 		}
-		if !info.Mode().IsRegular() {
+		if !info.Mode().IsRegular() || !opts.Filter.Keep(rel) {
 			return nil
 		}
-		rel, err := filepath.Rel(root, path)
-		if err != nil {
-			return err
-		}
-		rel = filepath.ToSlash(rel)
-		files = append(files, entry{path: rel, mode: info.Mode().Perm()})
+		files = append(files, File{Path: rel, Mode: info.Mode().Perm()})
 		return nil
 	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
-	h := sha256.New()
-	for _, entry := range files {
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	for i := range files {
 		if err := ctx.Err(); err != nil {
-			return "", err
+			return nil, err
 		}
-		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(entry.path)))
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(files[i].Path)))
 		if err != nil {
-			return "", err
+			return nil, err
+		}
+		if opts.Normalize == NormalizeText {
+			data = normalizeText(data)
 		}
-		fh := sha256.Sum256(data)
-		line := fmt.Sprintf("%x  %o  %s\n", fh, entry.mode, entry.path)
+		files[i].Sum = fmt.Sprintf("%x", sha256.Sum256(data))
+	}
+	return files, nil
+}
+
+// Sum combines per-file digests, sorted by path, into a directory hash.
+func Sum(files []File) string {
+	h := sha256.New()
+	for _, file := range files {
+		line := fmt.Sprintf("%s  %o  %s\n", file.Sum, file.Mode, file.Path)
 		h.Write([]byte(line))
 	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
 
-	return fmt.Sprintf("%x", h.Sum(nil)), nil
+// IsBinary reports whether data looks like binary content.
+// Like git, it treats any NUL byte in the first few kilobytes as binary.
+func IsBinary(data []byte) bool {
+	if len(data) > sniffLen {
+		data = data[:sniffLen]
+	}
+	return bytes.IndexByte(data, 0) != -1
+}
+
+// normalizeText converts CRLF line endings to LF unless data looks binary.
+func normalizeText(data []byte) []byte {
+	if IsBinary(data) || !bytes.Contains(data, []byte("\r\n")) {
+		return data
+	}
+	return bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
 }
 
 func NormalizePath(path string) string {
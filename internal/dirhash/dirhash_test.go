@@ -1,6 +1,7 @@
 package dirhash
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -85,3 +86,66 @@ func TestHashDirDetectsModeChange(t *testing.T) {
 		t.Fatalf("expected hash to change when mode changes")
 	}
 }
+
+func TestHashDirNormalizeText(t *testing.T) {
+	unix := t.TempDir()
+	windows := t.TempDir()
+	if err := os.WriteFile(filepath.Join(unix, "SKILL.md"), []byte("line one\nline two\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(windows, "SKILL.md"), []byte("line one\r\nline two\r\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	ctx := context.Background()
+	raw1, err := HashDirWithOptions(ctx, unix, Options{})
+	if err != nil {
+		t.Fatalf("hash dir: %v", err)
+	}
+	raw2, err := HashDirWithOptions(ctx, windows, Options{})
+	if err != nil {
+		t.Fatalf("hash dir: %v", err)
+	}
+	if raw1 == raw2 {
+		t.Fatalf("expected raw hashes to differ for CRLF content")
+	}
+
+	text1, err := HashDirWithOptions(ctx, unix, Options{Normalize: NormalizeText})
+	if err != nil {
+		t.Fatalf("hash dir: %v", err)
+	}
+	text2, err := HashDirWithOptions(ctx, windows, Options{Normalize: NormalizeText})
+	if err != nil {
+		t.Fatalf("hash dir: %v", err)
+	}
+	if text1 != text2 {
+		t.Fatalf("expected text hashes to match; got %q and %q", text1, text2)
+	}
+	if text1 != raw1 {
+		t.Fatalf("expected text mode to leave LF content unchanged")
+	}
+}
+
+func TestHashDirNormalizeTextSkipsBinary(t *testing.T) {
+	a := t.TempDir()
+	b := t.TempDir()
+	if err := os.WriteFile(filepath.Join(a, "blob.bin"), []byte("\x00\x01\r\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(b, "blob.bin"), []byte("\x00\x01\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	ctx := context.Background()
+	hashA, err := HashDirWithOptions(ctx, a, Options{Normalize: NormalizeText})
+	if err != nil {
+		t.Fatalf("hash dir: %v", err)
+	}
+	hashB, err := HashDirWithOptions(ctx, b, Options{Normalize: NormalizeText})
+	if err != nil {
+		t.Fatalf("hash dir: %v", err)
+	}
+	if hashA == hashB {
+		t.Fatalf("expected binary content to be hashed byte-for-byte")
+	}
+}
@@ -1,6 +1,8 @@
 package e2e
 
 import (
+	"context"
+	"encoding/json"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -93,13 +95,15 @@ func cmdLockCmp(ts *testscript.TestScript, neg bool, args []string) {
 			values["__LOCAL__"] = value
 		case key == "checksum":
 			values["__CHECKSUM__"] = mustHashDir(ts, value)
+			values["__FILES__"] = mustFileSums(ts, value)
 		case strings.HasPrefix(key, "checksum."):
 			name := strings.TrimPrefix(key, "checksum.")
 			if name == "" {
 				ts.Fatalf("lockcmp checksum key must include a name: %q", key)
 			}
-			token := "__CHECKSUM_" + strings.ToUpper(name) + "__"
-			values[token] = mustHashDir(ts, value)
+			suffix := strings.ToUpper(name) + "__"
+			values["__CHECKSUM_"+suffix] = mustHashDir(ts, value)
+			values["__FILES_"+suffix] = mustFileSums(ts, value)
 		default:
 			ts.Fatalf("unknown lockcmp key %q", key)
 		}
@@ -167,6 +171,20 @@ func mustHashDir(ts *testscript.TestScript, dir string) string {
 	return sum
 }
 
+// mustFileSums renders the per-file digests of dir the way they appear in a
+// skill entry of skv.lock.
+func mustFileSums(ts *testscript.TestScript, dir string) string {
+	files, err := dirhash.Files(context.Background(), ts.MkAbs(dir), dirhash.Options{})
+	ts.Check(err)
+	sums := make(map[string]string, len(files))
+	for _, file := range files {
+		sums[file.Path] = file.Sum
+	}
+	data, err := json.MarshalIndent(sums, "      ", "  ")
+	ts.Check(err)
+	return string(data)
+}
+
 func repoURL(ts *testscript.TestScript, repo string) string {
 	if strings.HasPrefix(repo, "file://") {
 		return repo
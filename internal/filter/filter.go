@@ -0,0 +1,147 @@
+// Package filter selects which files of a skill directory are vendored and
+// hashed, using gitignore-style globs.
+package filter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// IgnoreFile is the name of the per-skill ignore file read from upstream.
+const IgnoreFile = ".skvignore"
+
+// alwaysKept files are never filtered out: without SKILL.md a directory is not
+// a skill, and keeping .skvignore lets the filter be rebuilt from a copy.
+var alwaysKept = []string{"SKILL.md", IgnoreFile}
+
+// Filter keeps files that match any Include pattern (every file if Include is
+// empty) and no Exclude pattern. Patterns are slash-separated globs relative to
+// the skill root. A pattern without a slash matches at any depth, "**" matches
+// any number of directories, and a pattern that matches a directory matches
+// everything under it. A nil Filter keeps every file.
+type Filter struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// IsEmpty reports whether f keeps every file.
+func (f *Filter) IsEmpty() bool {
+	return f == nil || (len(f.Include) == 0 && len(f.Exclude) == 0)
+}
+
+// Equal reports whether f and g have the same patterns; nil equals empty.
+func (f *Filter) Equal(g *Filter) bool {
+	if f.IsEmpty() || g.IsEmpty() {
+		return f.IsEmpty() == g.IsEmpty()
+	}
+	return slices.Equal(f.Include, g.Include) && slices.Equal(f.Exclude, g.Exclude)
+}
+
+// Validate reports the first malformed pattern.
+func (f *Filter) Validate() error {
+	if f == nil {
+		return nil
+	}
+	for _, pattern := range append(append([]string{}, f.Include...), f.Exclude...) {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("empty file pattern")
+		}
+		for _, segment := range strings.Split(strings.Trim(pattern, "/"), "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Keep reports whether the file at the slash-separated path rel is kept.
+// SKILL.md and .skvignore at the root are always kept.
+func (f *Filter) Keep(rel string) bool {
+	if f == nil || slices.Contains(alwaysKept, rel) {
+		return true
+	}
+	if f.SkipDir(rel) {
+		return false
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, pattern := range f.Include {
+		if Match(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// SkipDir reports whether everything under the directory rel is excluded.
+func (f *Filter) SkipDir(rel string) bool {
+	if f == nil {
+		return false
+	}
+	for _, pattern := range f.Exclude {
+		if Match(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether pattern matches rel or one of its parent directories.
+func Match(pattern, rel string) bool {
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	segments := strings.Split(strings.TrimPrefix(pattern, "/"), "/")
+	if !anchored {
+		segments = append([]string{"**"}, segments...)
+	}
+	parts := strings.Split(rel, "/")
+	for i := len(parts); i > 0; i-- {
+		if matchSegments(segments, parts[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(segments, parts []string) bool {
+	if len(segments) == 0 {
+		return len(parts) == 0
+	}
+	if segments[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(segments[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, err := path.Match(segments[0], parts[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(segments[1:], parts[1:])
+}
+
+// ParseIgnore returns the patterns in a .skvignore file: one per line, with
+// blank lines and # comments skipped. Negated (!) patterns are not supported
+// and are skipped too.
+func ParseIgnore(data []byte) []string {
+	var patterns []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns
+}
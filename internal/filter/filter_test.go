@@ -0,0 +1,75 @@
+package filter
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		pattern, rel string
+		want         bool
+	}{
+		{"*.png", "shot.png", true},
+		{"*.png", "docs/img/shot.png", true},
+		{"*.png", "shot.png.txt", false},
+		{"tests", "tests/unit/a_test.md", true},
+		{"tests/", "tests/a.md", true},
+		{"tests", "src/tests/a.md", true},
+		{"/tests", "src/tests/a.md", false},
+		{"docs/*.md", "docs/a.md", true},
+		{"docs/*.md", "docs/deep/a.md", false},
+		{"docs/**/*.md", "docs/deep/a.md", true},
+		{"docs/**/*.md", "docs/a.md", true},
+		{"examples/large", "examples/large/data.bin", true},
+		{"examples/large", "other/examples/large/data.bin", false},
+	}
+	for _, tc := range cases {
+		if got := Match(tc.pattern, tc.rel); got != tc.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tc.pattern, tc.rel, got, tc.want)
+		}
+	}
+}
+
+func TestKeep(t *testing.T) {
+	f := &Filter{Include: []string{"*.md", "scripts"}, Exclude: []string{"drafts", "*.draft.md"}}
+	cases := map[string]bool{
+		"SKILL.md":          true,
+		"README.md":         true,
+		"docs/guide.md":     true,
+		"scripts/run.sh":    true,
+		"image.png":         false,
+		"drafts/idea.md":    false,
+		"notes.draft.md":    false,
+		"scripts/drafts/x":  false,
+		"scripts/README.md": true,
+	}
+	for rel, want := range cases {
+		if got := f.Keep(rel); got != want {
+			t.Errorf("Keep(%q) = %v, want %v", rel, got, want)
+		}
+	}
+
+	if !(&Filter{Exclude: []string{"SKILL.md"}}).Keep("SKILL.md") {
+		t.Errorf("SKILL.md must always be kept")
+	}
+	var none *Filter
+	if !none.Keep("anything") || !none.IsEmpty() {
+		t.Errorf("nil filter must keep everything")
+	}
+}
+
+func TestParseIgnore(t *testing.T) {
+	got := ParseIgnore([]byte("# comment\n\ntests/\n  *.png  \n!keep.png\n"))
+	want := []string{"tests/", "*.png"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("ParseIgnore = %q, want %q", got, want)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	var none *Filter
+	if !none.Equal(&Filter{}) {
+		t.Errorf("nil and empty filters should be equal")
+	}
+	if (&Filter{Exclude: []string{"a"}}).Equal(&Filter{Exclude: []string{"b"}}) {
+		t.Errorf("different patterns should not be equal")
+	}
+}
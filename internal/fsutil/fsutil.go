@@ -6,13 +6,31 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+
+	"github.com/skill-vendor/skv/internal/filter"
 )
 
 func EnsureDir(path string) error {
 	return os.MkdirAll(path, 0o755)
 }
 
-func CopyDir(src, dst string) error {
+// CopyDir copies the regular files under src that f keeps (all if f is nil)
+// to dst.
+func CopyDir(src, dst string, f *filter.Filter) error {
+	return walkFiles(src, dst, f, copyFile)
+}
+
+// LinkDir recreates the directory tree of src at dst with every regular file
+// that f keeps hard-linked to its source.
+func LinkDir(src, dst string, f *filter.Filter) error {
+	return walkFiles(src, dst, f, func(path, target string, _ fs.FileMode) error {
+		return os.Link(path, target)
+	})
+}
+
+// walkFiles mirrors the directories under src at dst and calls place for each
+// regular file f keeps. Like CopyDir it skips .git and refuses symlinks.
+func walkFiles(src, dst string, f *filter.Filter, place func(path, target string, perm fs.FileMode) error) error {
 	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
@@ -25,9 +43,16 @@ func CopyDir(src, dst string) error {
 			return err
 		}
 		target := filepath.Join(dst, rel)
+		slashRel := filepath.ToSlash(rel)
 		if d.IsDir() {
+			if rel != "." && f.SkipDir(slashRel) {
+				return fs.SkipDir
+			}
 			return os.MkdirAll(target, 0o755)
 		}
+		if !f.Keep(slashRel) {
+			return nil
+		}
 		info, err := d.Info()
 		if err != nil {
 			return err
@@ -38,7 +63,7 @@ func CopyDir(src, dst string) error {
 		if !info.Mode().IsRegular() {
 			return nil
 		}
-		if err := copyFile(path, target, info.Mode().Perm()); err != nil {
+		if err := place(path, target, info.Mode().Perm()); err != nil {
 			return err
 		}
 		return nil
@@ -0,0 +1,205 @@
+// Package license identifies the SPDX license of a license file, either from
+// an SPDX-License-Identifier line or by comparing its text against an
+// embedded corpus of license texts from the SPDX License List.
+package license
+
+import (
+	"bufio"
+	"embed"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:embed corpus/*.txt
+var corpusFS embed.FS
+
+// MinConfidence is the similarity a text needs to be identified as a corpus
+// license.
+const MinConfidence = 0.8
+
+// Match is the license a text was identified as.
+type Match struct {
+	// ID is an SPDX license identifier or, from a header, an SPDX expression.
+	ID string
+	// Confidence is the similarity to the corpus text, from 0 to 1; it is 1
+	// for an SPDX-License-Identifier header.
+	Confidence float64
+}
+
+// headerLines is how many leading lines are searched for an
+// SPDX-License-Identifier header.
+const headerLines = 30
+
+var (
+	headerPattern     = regexp.MustCompile(`SPDX-License-Identifier:\s*(.+?)\s*(?:\*/|-->)?\s*$`)
+	expressionPattern = regexp.MustCompile(`^\(*[A-Za-z0-9][A-Za-z0-9.+-]*\)*(?:\s+(?:AND|OR|WITH)\s+\(*[A-Za-z0-9][A-Za-z0-9.+-]*\)*)*$`)
+)
+
+// Header returns the license expression of the first SPDX-License-Identifier
+// line near the top of text.
+func Header(text string) (string, bool) {
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for n := 0; n < headerLines && scanner.Scan(); n++ {
+		match := headerPattern.FindStringSubmatch(scanner.Text())
+		if match != nil && expressionPattern.MatchString(match[1]) {
+			return match[1], true
+		}
+	}
+	return "", false
+}
+
+// Identify returns the license of text. An SPDX-License-Identifier header
+// wins; otherwise text is scored against every corpus license and the most
+// similar one is returned. The result is false, with the closest candidate,
+// when no header is found and the best score is below MinConfidence.
+func Identify(text string) (Match, bool) {
+	if id, ok := Header(text); ok {
+		return Match{ID: id, Confidence: 1}, true
+	}
+	words := bigrams(normalize(text))
+	if len(words) == 0 {
+		return Match{}, false
+	}
+	var best Match
+	for _, entry := range loadCorpus() {
+		if score := dice(words, entry.bigrams); score > best.Confidence {
+			best = Match{ID: entry.id, Confidence: score}
+		}
+	}
+	best.Confidence = math.Floor(best.Confidence*100) / 100
+	return best, best.Confidence >= MinConfidence
+}
+
+// IDs returns the identifiers of the corpus licenses, sorted.
+func IDs() []string {
+	var ids []string
+	for _, entry := range loadCorpus() {
+		ids = append(ids, entry.id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Satisfies reports whether the SPDX expression expr can be used under the
+// allowed identifiers: one alternative of an OR must have every AND term
+// allowed. Exceptions after WITH are ignored.
+func Satisfies(expr string, allowed []string) bool {
+	expr = strings.NewReplacer("(", " ", ")", " ").Replace(expr)
+	for _, alternative := range splitOperator(expr, "OR") {
+		ok := true
+		for _, term := range splitOperator(alternative, "AND") {
+			id, _, _ := strings.Cut(strings.TrimSpace(term), " WITH ")
+			if !containsFold(allowed, strings.TrimSpace(id)) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func splitOperator(expr, op string) []string {
+	fields := strings.Fields(expr)
+	var parts []string
+	var current []string
+	for _, field := range fields {
+		if field == op {
+			parts = append(parts, strings.Join(current, " "))
+			current = nil
+			continue
+		}
+		current = append(current, field)
+	}
+	return append(parts, strings.Join(current, " "))
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+type corpusEntry struct {
+	id      string
+	bigrams map[string]int
+}
+
+var loadCorpus = sync.OnceValue(func() []corpusEntry {
+	files, err := corpusFS.ReadDir("corpus")
+	if err != nil {
+		panic(err)
+	}
+	entries := make([]corpusEntry, 0, len(files))
+	for _, file := range files {
+		data, err := corpusFS.ReadFile(path.Join("corpus", file.Name()))
+		if err != nil {
+			panic(err)
+		}
+		entries = append(entries, corpusEntry{
+			id:      strings.TrimSuffix(file.Name(), ".txt"),
+			bigrams: bigrams(normalize(string(data))),
+		})
+	}
+	return entries
+})
+
+var (
+	placeholder = regexp.MustCompile(`<[^<>\n]{1,80}>`)
+	nonWord     = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// normalize reduces a license text to the words that carry its terms:
+// lowercased, without punctuation, placeholders, copyright lines, or the
+// single-character tokens used to number clauses.
+func normalize(text string) []string {
+	var words []string
+	for _, line := range strings.Split(strings.ToLower(text), "\n") {
+		trimmed := strings.TrimLeft(line, " \t#*/;-")
+		if strings.HasPrefix(trimmed, "copyright") || strings.HasPrefix(trimmed, "(c)") || strings.HasPrefix(trimmed, "©") {
+			continue
+		}
+		line = placeholder.ReplaceAllString(line, " ")
+		line = strings.ReplaceAll(line, "&", " and ")
+		for _, word := range strings.Fields(nonWord.ReplaceAllString(line, " ")) {
+			if len(word) > 1 {
+				words = append(words, word)
+			}
+		}
+	}
+	return words
+}
+
+// bigrams counts the adjacent word pairs in words.
+func bigrams(words []string) map[string]int {
+	counts := make(map[string]int, len(words))
+	for i := 0; i+1 < len(words); i++ {
+		counts[words[i]+" "+words[i+1]]++
+	}
+	return counts
+}
+
+// dice returns the Sørensen–Dice coefficient of two bigram multisets.
+func dice(a, b map[string]int) float64 {
+	total, shared := 0, 0
+	for gram, n := range a {
+		total += n
+		shared += min(n, b[gram])
+	}
+	for _, n := range b {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	return 2 * float64(shared) / float64(total)
+}
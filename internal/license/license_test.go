@@ -0,0 +1,94 @@
+package license
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIdentifyCorpus(t *testing.T) {
+	for _, id := range IDs() {
+		data, err := corpusFS.ReadFile("corpus/" + id + ".txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		text := "Copyright (c) 2026 Example Authors\n\n" + string(data)
+		match, ok := Identify(text)
+		if !ok || match.ID != id {
+			t.Errorf("Identify(%s) = %+v, %v; want %s", id, match, ok, id)
+		}
+	}
+}
+
+func TestIdentifyDistinguishesVariants(t *testing.T) {
+	data, err := corpusFS.ReadFile("corpus/BSD-3-Clause.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	match, ok := Identify(string(data))
+	if !ok || match.ID != "BSD-3-Clause" {
+		t.Fatalf("Identify(BSD-3-Clause) = %+v, %v", match, ok)
+	}
+	// Dropping the non-endorsement clause leaves BSD-2-Clause.
+	start := strings.Index(string(data), "3. Neither")
+	end := strings.Index(string(data), "THIS SOFTWARE")
+	match, ok = Identify(string(data[:start]) + string(data[end:]))
+	if !ok || match.ID != "BSD-2-Clause" {
+		t.Fatalf("Identify(BSD-3-Clause without clause 3) = %+v, %v; want BSD-2-Clause", match, ok)
+	}
+}
+
+func TestIdentifyUnknown(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"MIT License\n\nCopyright (c) 2000 Example\n",
+		"All rights reserved. Licensed under the Apache License, see the website for details.\n",
+	} {
+		if match, ok := Identify(text); ok {
+			t.Errorf("Identify(%q) = %+v, want no match", text, match)
+		}
+	}
+}
+
+func TestHeader(t *testing.T) {
+	cases := []struct {
+		text string
+		want string
+	}{
+		{"// SPDX-License-Identifier: MIT\npackage x\n", "MIT"},
+		{"<!-- SPDX-License-Identifier: Apache-2.0 OR MIT -->\n# Skill\n", "Apache-2.0 OR MIT"},
+		{"/* SPDX-License-Identifier: GPL-2.0-only WITH Classpath-exception-2.0 */\n", "GPL-2.0-only WITH Classpath-exception-2.0"},
+		{"# SPDX-License-Identifier: see LICENSE file\n", ""},
+		{strings.Repeat("\n", headerLines) + "SPDX-License-Identifier: MIT\n", ""},
+	}
+	for _, c := range cases {
+		got, ok := Header(c.text)
+		if got != c.want || ok != (c.want != "") {
+			t.Errorf("Header(%q) = %q, %v; want %q", c.text, got, ok, c.want)
+		}
+	}
+	match, ok := Identify("SPDX-License-Identifier: ISC\n")
+	if !ok || match != (Match{ID: "ISC", Confidence: 1}) {
+		t.Errorf("Identify(header) = %+v, %v", match, ok)
+	}
+}
+
+func TestSatisfies(t *testing.T) {
+	allowed := []string{"MIT", "Apache-2.0"}
+	cases := []struct {
+		expr string
+		want bool
+	}{
+		{"MIT", true},
+		{"mit", true},
+		{"GPL-3.0-only", false},
+		{"Apache-2.0 OR GPL-2.0-only", true},
+		{"MIT AND GPL-2.0-only", false},
+		{"(MIT AND Apache-2.0) OR GPL-3.0-only", true},
+		{"Apache-2.0 WITH LLVM-exception", true},
+	}
+	for _, c := range cases {
+		if got := Satisfies(c.expr, allowed); got != c.want {
+			t.Errorf("Satisfies(%q) = %v, want %v", c.expr, got, c.want)
+		}
+	}
+}
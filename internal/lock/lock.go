@@ -3,26 +3,87 @@ package lock
 import (
 	"encoding/json"
 	"os"
+	"sort"
+
+	"github.com/skill-vendor/skv/internal/filter"
 )
 
 type Lock struct {
-	Skills []Skill `json:"skills"`
+	// Normalize records the checksum normalization mode used for every skill.
+	Normalize string  `json:"normalize,omitempty"`
+	Skills    []Skill `json:"skills"`
 }
 
 type Skill struct {
-	Name     string   `json:"name"`
-	Local    string   `json:"local,omitempty"`
-	Repo     string   `json:"repo,omitempty"`
+	Name     string `json:"name"`
+	Local    string `json:"local,omitempty"`
+	Repo     string `json:"repo,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Ref      string `json:"ref,omitempty"`
+	Commit   string `json:"commit,omitempty"`
+	Checksum string `json:"checksum"`
+	// UpstreamChecksum is the checksum of the fetched content before Patches
+	// were applied; Checksum covers the patched result.
+	UpstreamChecksum string  `json:"upstreamChecksum,omitempty"`
+	Patches          []Patch `json:"patches,omitempty"`
+	// Files maps each vendored file (slash-separated path) to its content digest,
+	// so drift can be reported per file.
+	Files   map[string]string `json:"files,omitempty"`
+	License *License          `json:"license,omitempty"`
+	// Metadata caches the SKILL.md frontmatter of the vendored content.
+	Metadata *Metadata `json:"metadata,omitempty"`
+	// Filter is the effective file filter the skill was vendored and hashed
+	// with: its files globs plus any upstream .skvignore patterns.
+	Filter *filter.Filter `json:"filter,omitempty"`
+	// ForkedFrom records the remote a local skill was ejected from.
+	ForkedFrom *Fork `json:"forkedFrom,omitempty"`
+	// Signer records the key that signed Commit, for skills that require
+	// signatures.
+	Signer *Signer `json:"signer,omitempty"`
+}
+
+// Signer is an allowed key whose signature was verified on a locked commit.
+type Signer struct {
+	Format string `json:"format"` // "ssh" or "gpg"
+	// Fingerprint is the SHA256 fingerprint of an SSH key or the primary key
+	// fingerprint of a GPG key.
+	Fingerprint string `json:"fingerprint"`
+	// Tag is the signed tag pointing at the commit, when it was the tag rather
+	// than the commit that carried the signature.
+	Tag string `json:"tag,omitempty"`
+}
+
+// Metadata is the name and description a skill declares in its SKILL.md.
+type Metadata struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Patch is a patch file applied to a skill, with the digest of its content.
+type Patch struct {
+	Path     string `json:"path"` // slash-separated, relative to the spec
+	Checksum string `json:"checksum"`
+}
+
+// Fork is the upstream a local skill was forked from, as it was locked at the
+// time of ejection.
+type Fork struct {
+	Repo     string   `json:"repo"`
 	Path     string   `json:"path,omitempty"`
 	Ref      string   `json:"ref,omitempty"`
-	Commit   string   `json:"commit,omitempty"`
-	Checksum string   `json:"checksum"`
+	Commit   string   `json:"commit"`
+	Checksum string   `json:"checksum,omitempty"`
 	License  *License `json:"license,omitempty"`
 }
 
+// License is the license detected for a skill. SPDX is empty when the file at
+// Path was not recognized.
 type License struct {
 	SPDX string `json:"spdx,omitempty"`
 	Path string `json:"path,omitempty"`
+	// Confidence is how closely the file matched the license text, from 0 to
+	// 1; it is 1 for an SPDX-License-Identifier header.
+	Confidence float64 `json:"confidence,omitempty"`
 }
 
 func Write(path string, lock *Lock) error {
@@ -49,3 +110,25 @@ func Load(path string) (*Lock, error) {
 	}
 	return &lock, nil
 }
+
+// Canonical returns the form of lock that signatures cover: compact JSON with
+// skills sorted by name, so reformatting or reordering the file does not
+// change it.
+func Canonical(lock *Lock) ([]byte, error) {
+	c := *lock
+	c.Skills = append([]Skill{}, lock.Skills...)
+	sort.SliceStable(c.Skills, func(i, j int) bool { return c.Skills[i].Name < c.Skills[j].Name })
+	data, err := json.Marshal(&c)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// ShortCommit abbreviates commit to seven characters for display.
+func ShortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
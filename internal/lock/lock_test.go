@@ -0,0 +1,59 @@
+package lock
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestCanonicalIgnoresOrderAndFormatting(t *testing.T) {
+	lock := &Lock{Skills: []Skill{
+		{Name: "bravo", Commit: "b1", Checksum: "y"},
+		{Name: "alpha", Commit: "a1", Checksum: "x", Files: map[string]string{"b": "2", "a": "1"}},
+	}}
+	want, err := Canonical(lock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "skv.lock")
+	if err := Write(path, lock); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded.Skills[0], loaded.Skills[1] = loaded.Skills[1], loaded.Skills[0]
+	got, err := Canonical(loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("canonical form changed:\n%s\n%s", got, want)
+	}
+	if lock.Skills[0].Name != "bravo" {
+		t.Error("Canonical reordered the caller's skills")
+	}
+
+	loaded.Skills[0].Checksum = "z"
+	if changed, _ := Canonical(loaded); bytes.Equal(changed, want) {
+		t.Error("canonical form did not change with the lock")
+	}
+	if empty, _ := Canonical(&Lock{}); string(empty) != "{\"skills\":[]}\n" {
+		t.Errorf("empty lock = %q", empty)
+	}
+}
+
+func TestShortCommit(t *testing.T) {
+	cases := map[string]string{
+		"":    "",
+		"abc": "abc",
+		"580f3991f0b4aed5bfda0beb2abe6d97637fb45c": "580f399",
+	}
+	for commit, want := range cases {
+		if got := ShortCommit(commit); got != want {
+			t.Errorf("ShortCommit(%q) = %q, want %q", commit, got, want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package lock
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Conflict describes a lock entry that was changed differently on both sides of a merge.
+type Conflict struct {
+	Name   string
+	Reason string
+}
+
+func (c Conflict) String() string {
+	if c.Name == "" {
+		return c.Reason
+	}
+	return fmt.Sprintf("%s: %s", c.Name, c.Reason)
+}
+
+// Merge performs a three-way merge of lock entries keyed by skill name.
+// Entries changed on only one side take that side's value, and entries
+// changed identically on both sides merge cleanly. When both sides change
+// an entry differently, ours is kept in the result and a Conflict is reported.
+// So is an entry added or changed on a side whose checksum mode the merge
+// does not keep, since its checksums must be rehashed.
+func Merge(base, ours, theirs *Lock) (*Lock, []Conflict) {
+	merged := &Lock{}
+	var conflicts []Conflict
+
+	switch {
+	case ours.Normalize == theirs.Normalize, theirs.Normalize == base.Normalize:
+		merged.Normalize = ours.Normalize
+	case ours.Normalize == base.Normalize:
+		merged.Normalize = theirs.Normalize
+	default:
+		merged.Normalize = ours.Normalize
+		conflicts = append(conflicts, Conflict{
+			Reason: fmt.Sprintf("checksum mode changed on both sides (%q vs %q)", ours.Normalize, theirs.Normalize),
+		})
+	}
+
+	baseMap := index(base)
+	oursMap := index(ours)
+	theirsMap := index(theirs)
+
+	names := make(map[string]struct{})
+	for _, m := range []map[string]Skill{baseMap, oursMap, theirsMap} {
+		for name := range m {
+			names[name] = struct{}{}
+		}
+	}
+
+	for name := range names {
+		b, inBase := baseMap[name]
+		o, inOurs := oursMap[name]
+		t, inTheirs := theirsMap[name]
+
+		oursChanged := inOurs != inBase || (inOurs && !reflect.DeepEqual(o, b))
+		theirsChanged := inTheirs != inBase || (inTheirs && !reflect.DeepEqual(t, b))
+		sameResult := inOurs == inTheirs && (!inOurs || reflect.DeepEqual(o, t))
+
+		switch {
+		case !theirsChanged || sameResult:
+			if inOurs {
+				merged.Skills = append(merged.Skills, o)
+				if oursChanged && !sameResult && ours.Normalize != merged.Normalize {
+					conflicts = append(conflicts, Conflict{Name: name, Reason: modeReason(ours.Normalize, merged.Normalize)})
+				}
+			}
+		case !oursChanged:
+			if inTheirs {
+				merged.Skills = append(merged.Skills, t)
+				if theirs.Normalize != merged.Normalize {
+					conflicts = append(conflicts, Conflict{Name: name, Reason: modeReason(theirs.Normalize, merged.Normalize)})
+				}
+			}
+		default:
+			if inOurs {
+				merged.Skills = append(merged.Skills, o)
+			}
+			conflicts = append(conflicts, Conflict{Name: name, Reason: conflictReason(o, t, inOurs, inTheirs)})
+		}
+	}
+
+	sort.Slice(merged.Skills, func(i, j int) bool { return merged.Skills[i].Name < merged.Skills[j].Name })
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Name < conflicts[j].Name })
+	return merged, conflicts
+}
+
+func modeReason(hashed, merged string) string {
+	return fmt.Sprintf("hashed with checksum mode %q, but the merged lock uses %q; run skv sync to rehash", hashed, merged)
+}
+
+func conflictReason(ours, theirs Skill, inOurs, inTheirs bool) string {
+	switch {
+	case !inOurs:
+		return "removed in ours but changed in theirs"
+	case !inTheirs:
+		return "removed in theirs but changed in ours"
+	case ours.Commit != theirs.Commit:
+		return fmt.Sprintf("locked to different commits (%s vs %s)", describeCommit(ours.Commit), describeCommit(theirs.Commit))
+	case ours.Checksum != theirs.Checksum:
+		return fmt.Sprintf("locked to different checksums (%s vs %s)", ours.Checksum, theirs.Checksum)
+	default:
+		return "changed differently on both sides"
+	}
+}
+
+func describeCommit(commit string) string {
+	if commit == "" {
+		return "(none)"
+	}
+	return ShortCommit(commit)
+}
+
+func index(lock *Lock) map[string]Skill {
+	m := make(map[string]Skill, len(lock.Skills))
+	for _, skill := range lock.Skills {
+		m[skill.Name] = skill
+	}
+	return m
+}
@@ -0,0 +1,110 @@
+package lock
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeKeepsAdditionsFromBothSides(t *testing.T) {
+	base := &Lock{Skills: []Skill{{Name: "alpha", Commit: "a1", Checksum: "x"}}}
+	ours := &Lock{Skills: []Skill{
+		{Name: "alpha", Commit: "a1", Checksum: "x"},
+		{Name: "bravo", Commit: "b1", Checksum: "y"},
+	}}
+	theirs := &Lock{Skills: []Skill{
+		{Name: "alpha", Commit: "a1", Checksum: "x"},
+		{Name: "charlie", Commit: "c1", Checksum: "z"},
+	}}
+
+	merged, conflicts := Merge(base, ours, theirs)
+	if len(conflicts) != 0 {
+		t.Fatalf("expected no conflicts, got %v", conflicts)
+	}
+	want := []Skill{
+		{Name: "alpha", Commit: "a1", Checksum: "x"},
+		{Name: "bravo", Commit: "b1", Checksum: "y"},
+		{Name: "charlie", Commit: "c1", Checksum: "z"},
+	}
+	if !reflect.DeepEqual(merged.Skills, want) {
+		t.Fatalf("merged skills mismatch: %#v", merged.Skills)
+	}
+}
+
+func TestMergeTakesOneSidedChangesAndRemovals(t *testing.T) {
+	base := &Lock{Skills: []Skill{
+		{Name: "alpha", Commit: "a1", Checksum: "x"},
+		{Name: "bravo", Commit: "b1", Checksum: "y"},
+	}}
+	ours := &Lock{Skills: []Skill{
+		{Name: "alpha", Commit: "a2", Checksum: "x2"},
+		{Name: "bravo", Commit: "b1", Checksum: "y"},
+	}}
+	theirs := &Lock{Skills: []Skill{
+		{Name: "alpha", Commit: "a1", Checksum: "x"},
+	}}
+
+	merged, conflicts := Merge(base, ours, theirs)
+	if len(conflicts) != 0 {
+		t.Fatalf("expected no conflicts, got %v", conflicts)
+	}
+	want := []Skill{{Name: "alpha", Commit: "a2", Checksum: "x2"}}
+	if !reflect.DeepEqual(merged.Skills, want) {
+		t.Fatalf("merged skills mismatch: %#v", merged.Skills)
+	}
+}
+
+func TestMergeReportsDifferentCommits(t *testing.T) {
+	base := &Lock{Skills: []Skill{}}
+	ours := &Lock{Skills: []Skill{{Name: "alpha", Commit: "1111111aaaa", Checksum: "x"}}}
+	theirs := &Lock{Skills: []Skill{{Name: "alpha", Commit: "2222222bbbb", Checksum: "y"}}}
+
+	merged, conflicts := Merge(base, ours, theirs)
+	if len(conflicts) != 1 {
+		t.Fatalf("expected one conflict, got %v", conflicts)
+	}
+	if !strings.Contains(conflicts[0].String(), "alpha: locked to different commits (1111111 vs 2222222)") {
+		t.Fatalf("unexpected conflict: %s", conflicts[0])
+	}
+	if len(merged.Skills) != 1 || merged.Skills[0].Commit != "1111111aaaa" {
+		t.Fatalf("expected ours to be kept for the conflicting entry, got %#v", merged.Skills)
+	}
+}
+
+func TestMergeIdenticalChangesAreClean(t *testing.T) {
+	base := &Lock{Skills: []Skill{}}
+	side := &Lock{Normalize: "text", Skills: []Skill{{Name: "alpha", Commit: "a1", Checksum: "x"}}}
+
+	merged, conflicts := Merge(base, side, side)
+	if len(conflicts) != 0 {
+		t.Fatalf("expected no conflicts, got %v", conflicts)
+	}
+	if merged.Normalize != "text" || len(merged.Skills) != 1 {
+		t.Fatalf("unexpected merge result: %#v", merged)
+	}
+}
+
+func TestMergeReportsEntriesHashedUnderTheOtherMode(t *testing.T) {
+	base := &Lock{Skills: []Skill{{Name: "alpha", Commit: "a1", Checksum: "x"}}}
+	switched := &Lock{Normalize: "text", Skills: []Skill{{Name: "alpha", Commit: "a1", Checksum: "x-text"}}}
+	added := &Lock{Skills: []Skill{
+		{Name: "alpha", Commit: "a1", Checksum: "x"},
+		{Name: "bravo", Commit: "b1", Checksum: "y"},
+	}}
+	want := `bravo: hashed with checksum mode "", but the merged lock uses "text"; run skv sync to rehash`
+
+	for _, sides := range [][2]*Lock{{switched, added}, {added, switched}} {
+		merged, conflicts := Merge(base, sides[0], sides[1])
+		if merged.Normalize != "text" {
+			t.Errorf("merged normalize = %q, want text", merged.Normalize)
+		}
+		if len(conflicts) != 1 || conflicts[0].String() != want {
+			t.Errorf("conflicts = %v, want [%s]", conflicts, want)
+		}
+	}
+
+	// Without new or changed entries on the other side, the switch merges cleanly.
+	if _, conflicts := Merge(base, switched, base); len(conflicts) != 0 {
+		t.Errorf("expected no conflicts, got %v", conflicts)
+	}
+}
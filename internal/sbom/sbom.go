@@ -0,0 +1,374 @@
+// Package sbom renders the skills in a lock as a software bill of materials in
+// CycloneDX 1.5 or SPDX 2.3 JSON.
+package sbom
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/skill-vendor/skv/internal/lock"
+)
+
+// Formats supported by Generate.
+const (
+	CycloneDX = "cyclonedx"
+	SPDX      = "spdx"
+)
+
+// Document describes the project the skills are vendored into.
+type Document struct {
+	// Name is the project, usually the repo directory name.
+	Name string
+	// ToolVersion is the skv version recorded as the creating tool.
+	ToolVersion string
+	// Created is the document timestamp.
+	Created time.Time
+}
+
+// Generate renders l in format. Remote skills are third-party components
+// located by repo, commit, and path; local skills are first-party. The output
+// depends only on doc and l, so it is reproducible for a fixed Created.
+func Generate(format string, doc Document, l *lock.Lock) ([]byte, error) {
+	var v any
+	switch format {
+	case CycloneDX:
+		v = cycloneDX(doc, l)
+	case SPDX:
+		v = spdx(doc, l)
+	default:
+		return nil, fmt.Errorf("unknown SBOM format %q (want %s or %s)", format, CycloneDX, SPDX)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// digest identifies the content of l, for document identifiers that must be
+// unique per SBOM but stable for the same lock.
+func digest(doc Document, l *lock.Lock) [sha256.Size]byte {
+	data, _ := json.Marshal(l)
+	return sha256.Sum256(append([]byte(doc.Name+"\x00"), data...))
+}
+
+// uuid formats the first 16 bytes of sum as a version 8 (custom) UUID.
+func uuid(sum [sha256.Size]byte) string {
+	b := sum[:16]
+	b[6] = b[6]&0x0f | 0x80
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+type cdxBOM struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type               string           `json:"type"`
+	BOMRef             string           `json:"bom-ref,omitempty"`
+	Name               string           `json:"name"`
+	Version            string           `json:"version,omitempty"`
+	Description        string           `json:"description,omitempty"`
+	Hashes             []cdxHash        `json:"hashes,omitempty"`
+	Licenses           []cdxLicense     `json:"licenses,omitempty"`
+	ExternalReferences []cdxExternalRef `json:"externalReferences,omitempty"`
+	Properties         []cdxProperty    `json:"properties,omitempty"`
+	Pedigree           *cdxPedigree     `json:"pedigree,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxLicense struct {
+	License    *cdxLicenseID `json:"license,omitempty"`
+	Expression string        `json:"expression,omitempty"`
+}
+
+type cdxLicenseID struct {
+	ID string `json:"id"`
+}
+
+type cdxExternalRef struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxPedigree struct {
+	Ancestors []cdxComponent `json:"ancestors"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+func cycloneDX(doc Document, l *lock.Lock) cdxBOM {
+	project := cdxComponent{Type: "application", BOMRef: "project", Name: doc.Name}
+	bom := cdxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + uuid(digest(doc, l)),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: doc.Created.UTC().Format(time.RFC3339),
+			Tools:     cdxTools{Components: []cdxComponent{{Type: "application", Name: "skv", Version: doc.ToolVersion}}},
+			Component: project,
+		},
+		Components:   []cdxComponent{},
+		Dependencies: []cdxDependency{{Ref: project.BOMRef, DependsOn: []string{}}},
+	}
+	for _, skill := range l.Skills {
+		component := cdxComponent{
+			Type:     "library",
+			BOMRef:   "skill:" + skill.Name,
+			Name:     skill.Name,
+			Version:  skill.Commit,
+			Hashes:   cdxHashes(skill.Checksum),
+			Licenses: cdxLicenses(skill.License),
+		}
+		if skill.Metadata != nil {
+			component.Description = skill.Metadata.Description
+		}
+		if skill.Local != "" {
+			component.Properties = []cdxProperty{
+				{Name: "skv:origin", Value: "first-party"},
+				{Name: "skv:local", Value: skill.Local},
+			}
+			if fork := skill.ForkedFrom; fork != nil {
+				ancestor := cdxComponent{
+					Type:               "library",
+					Name:               skill.Name,
+					Version:            fork.Commit,
+					Hashes:             cdxHashes(fork.Checksum),
+					Licenses:           cdxLicenses(fork.License),
+					ExternalReferences: []cdxExternalRef{{Type: "vcs", URL: fork.Repo}},
+					Properties:         cdxSourceProperties(fork.Path, fork.Ref, fork.Commit),
+				}
+				component.Pedigree = &cdxPedigree{Ancestors: []cdxComponent{ancestor}}
+			}
+		} else {
+			component.ExternalReferences = []cdxExternalRef{{Type: "vcs", URL: skill.Repo}}
+			component.Properties = append([]cdxProperty{{Name: "skv:origin", Value: "third-party"}},
+				cdxSourceProperties(skill.Path, skill.Ref, skill.Commit)...)
+		}
+		bom.Components = append(bom.Components, component)
+		bom.Dependencies[0].DependsOn = append(bom.Dependencies[0].DependsOn, component.BOMRef)
+	}
+	return bom
+}
+
+func cdxHashes(checksum string) []cdxHash {
+	if checksum == "" {
+		return nil
+	}
+	return []cdxHash{{Alg: "SHA-256", Content: checksum}}
+}
+
+func cdxLicenses(license *lock.License) []cdxLicense {
+	if license == nil || license.SPDX == "" {
+		return nil
+	}
+	if strings.Contains(license.SPDX, " ") {
+		return []cdxLicense{{Expression: license.SPDX}}
+	}
+	return []cdxLicense{{License: &cdxLicenseID{ID: license.SPDX}}}
+}
+
+func cdxSourceProperties(path, ref, commit string) []cdxProperty {
+	var props []cdxProperty
+	if path != "" {
+		props = append(props, cdxProperty{Name: "skv:path", Value: path})
+	}
+	if ref != "" {
+		props = append(props, cdxProperty{Name: "skv:ref", Value: ref})
+	}
+	return append(props, cdxProperty{Name: "skv:commit", Value: commit})
+}
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID                string         `json:"SPDXID"`
+	Name                  string         `json:"name"`
+	VersionInfo           string         `json:"versionInfo,omitempty"`
+	Supplier              string         `json:"supplier,omitempty"`
+	DownloadLocation      string         `json:"downloadLocation"`
+	FilesAnalyzed         bool           `json:"filesAnalyzed"`
+	Checksums             []spdxChecksum `json:"checksums,omitempty"`
+	LicenseConcluded      string         `json:"licenseConcluded"`
+	LicenseDeclared       string         `json:"licenseDeclared"`
+	CopyrightText         string         `json:"copyrightText"`
+	Description           string         `json:"description,omitempty"`
+	Comment               string         `json:"comment,omitempty"`
+	PrimaryPackagePurpose string         `json:"primaryPackagePurpose,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+const noAssertion = "NOASSERTION"
+
+// spdxIDInvalid matches the characters an SPDX identifier may not contain.
+var spdxIDInvalid = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+func spdx(doc Document, l *lock.Lock) spdxDocument {
+	project := spdxPackage{
+		SPDXID:                "SPDXRef-Project",
+		Name:                  doc.Name,
+		DownloadLocation:      noAssertion,
+		LicenseConcluded:      noAssertion,
+		LicenseDeclared:       noAssertion,
+		CopyrightText:         noAssertion,
+		PrimaryPackagePurpose: "APPLICATION",
+	}
+	out := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              doc.Name,
+		DocumentNamespace: fmt.Sprintf("https://spdx.org/spdxdocs/%s-%s", spdxIDInvalid.ReplaceAllString(doc.Name, "-"), uuid(digest(doc, l))),
+		CreationInfo: spdxCreationInfo{
+			Created:  doc.Created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: skv-" + doc.ToolVersion},
+		},
+		Packages: []spdxPackage{project},
+		Relationships: []spdxRelationship{
+			{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: project.SPDXID},
+		},
+	}
+	for _, skill := range l.Skills {
+		id := "SPDXRef-Skill-" + spdxIDInvalid.ReplaceAllString(skill.Name, "-")
+		pkg := spdxPackage{
+			SPDXID:                id,
+			Name:                  skill.Name,
+			VersionInfo:           skill.Commit,
+			DownloadLocation:      spdxDownloadLocation(skill.Repo, skill.Commit, skill.Path),
+			Checksums:             spdxChecksums(skill.Checksum),
+			LicenseConcluded:      noAssertion,
+			LicenseDeclared:       spdxLicense(skill.License),
+			CopyrightText:         noAssertion,
+			PrimaryPackagePurpose: "LIBRARY",
+		}
+		if skill.Metadata != nil {
+			pkg.Description = skill.Metadata.Description
+		}
+		relationship := "DEPENDS_ON"
+		if skill.Local != "" {
+			// A local skill is part of the project, not a dependency of it.
+			relationship = "CONTAINS"
+			pkg.DownloadLocation = noAssertion
+			pkg.Comment = "First-party skill at " + skill.Local
+		} else {
+			pkg.Supplier = noAssertion
+			pkg.Comment = "Third-party skill from " + skill.Repo
+			if skill.Ref != "" {
+				pkg.Comment += " at ref " + skill.Ref
+			}
+		}
+		out.Packages = append(out.Packages, pkg)
+		out.Relationships = append(out.Relationships, spdxRelationship{
+			SPDXElementID: project.SPDXID, RelationshipType: relationship, RelatedSPDXElement: id,
+		})
+		if fork := skill.ForkedFrom; fork != nil {
+			upstream := spdxPackage{
+				SPDXID:           id + "-upstream",
+				Name:             skill.Name,
+				VersionInfo:      fork.Commit,
+				Supplier:         noAssertion,
+				DownloadLocation: spdxDownloadLocation(fork.Repo, fork.Commit, fork.Path),
+				Checksums:        spdxChecksums(fork.Checksum),
+				LicenseConcluded: noAssertion,
+				LicenseDeclared:  spdxLicense(fork.License),
+				CopyrightText:    noAssertion,
+				Comment:          "Upstream that " + skill.Name + " was forked from",
+			}
+			out.Packages = append(out.Packages, upstream)
+			out.Relationships = append(out.Relationships, spdxRelationship{
+				SPDXElementID: id, RelationshipType: "DESCENDANT_OF", RelatedSPDXElement: upstream.SPDXID,
+			})
+		}
+	}
+	return out
+}
+
+// spdxDownloadLocation formats a VCS location as git+<repo>@<commit>#<path>.
+func spdxDownloadLocation(repo, commit, path string) string {
+	if repo == "" {
+		return noAssertion
+	}
+	location := repo
+	if !strings.HasPrefix(location, "git+") {
+		location = "git+" + location
+	}
+	if commit != "" {
+		location += "@" + commit
+	}
+	if path != "" {
+		location += "#" + path
+	}
+	return location
+}
+
+func spdxChecksums(checksum string) []spdxChecksum {
+	if checksum == "" {
+		return nil
+	}
+	return []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: checksum}}
+}
+
+func spdxLicense(license *lock.License) string {
+	if license == nil || license.SPDX == "" {
+		return noAssertion
+	}
+	return license.SPDX
+}
@@ -0,0 +1,152 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/skill-vendor/skv/internal/lock"
+)
+
+var testLock = &lock.Lock{Skills: []lock.Skill{
+	{
+		Name:     "review",
+		Repo:     "https://github.com/acme/skills",
+		Path:     "skills/review",
+		Ref:      "v1.2.0",
+		Commit:   "0123456789abcdef0123456789abcdef01234567",
+		Checksum: "aaaa",
+		License:  &lock.License{SPDX: "Apache-2.0 OR MIT", Path: "LICENSE", Confidence: 1},
+	},
+	{
+		Name:     "notes",
+		Local:    "./.skv/skills/notes",
+		Checksum: "bbbb",
+		License:  &lock.License{Path: "LICENSE"},
+		ForkedFrom: &lock.Fork{
+			Repo:     "https://github.com/acme/notes",
+			Commit:   "89abcdef0123456789abcdef0123456789abcdef",
+			Checksum: "cccc",
+			License:  &lock.License{SPDX: "MIT", Path: "LICENSE", Confidence: 0.99},
+		},
+	},
+}}
+
+var testDocument = Document{Name: "app", ToolVersion: "v1.0.0", Created: time.Unix(1700000000, 0)}
+
+func TestCycloneDX(t *testing.T) {
+	data, err := Generate(CycloneDX, testDocument, testLock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bom cdxBOM
+	if err := json.Unmarshal(data, &bom); err != nil {
+		t.Fatal(err)
+	}
+	if bom.BOMFormat != "CycloneDX" || bom.Metadata.Timestamp != "2023-11-14T22:13:20Z" || bom.Metadata.Component.Name != "app" {
+		t.Fatalf("unexpected header: %+v", bom)
+	}
+	if len(bom.Components) != 2 {
+		t.Fatalf("got %d components, want 2", len(bom.Components))
+	}
+
+	remote := bom.Components[0]
+	if remote.Version != testLock.Skills[0].Commit || remote.Hashes[0] != (cdxHash{Alg: "SHA-256", Content: "aaaa"}) {
+		t.Errorf("remote component = %+v", remote)
+	}
+	if remote.ExternalReferences[0].URL != "https://github.com/acme/skills" || remote.Licenses[0].Expression != "Apache-2.0 OR MIT" {
+		t.Errorf("remote component = %+v", remote)
+	}
+	if !hasProperty(remote.Properties, "skv:origin", "third-party") || !hasProperty(remote.Properties, "skv:path", "skills/review") {
+		t.Errorf("remote properties = %+v", remote.Properties)
+	}
+
+	local := bom.Components[1]
+	if local.Version != "" || local.Licenses != nil || !hasProperty(local.Properties, "skv:origin", "first-party") {
+		t.Errorf("local component = %+v", local)
+	}
+	if local.Pedigree == nil || local.Pedigree.Ancestors[0].Version != testLock.Skills[1].ForkedFrom.Commit ||
+		local.Pedigree.Ancestors[0].Licenses[0].License.ID != "MIT" {
+		t.Errorf("local pedigree = %+v", local.Pedigree)
+	}
+	if got := bom.Dependencies[0].DependsOn; len(got) != 2 || got[0] != "skill:review" || got[1] != "skill:notes" {
+		t.Errorf("dependencies = %+v", bom.Dependencies)
+	}
+}
+
+func TestSPDX(t *testing.T) {
+	data, err := Generate(SPDX, testDocument, testLock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc spdxDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.SPDXVersion != "SPDX-2.3" || doc.CreationInfo.Created != "2023-11-14T22:13:20Z" {
+		t.Fatalf("unexpected header: %+v", doc)
+	}
+	packages := map[string]spdxPackage{}
+	for _, pkg := range doc.Packages {
+		packages[pkg.SPDXID] = pkg
+	}
+	remote := packages["SPDXRef-Skill-review"]
+	if remote.DownloadLocation != "git+https://github.com/acme/skills@0123456789abcdef0123456789abcdef01234567#skills/review" ||
+		remote.LicenseDeclared != "Apache-2.0 OR MIT" || remote.Checksums[0].ChecksumValue != "aaaa" {
+		t.Errorf("remote package = %+v", remote)
+	}
+	local := packages["SPDXRef-Skill-notes"]
+	if local.DownloadLocation != noAssertion || local.LicenseDeclared != noAssertion {
+		t.Errorf("local package = %+v", local)
+	}
+	if upstream := packages["SPDXRef-Skill-notes-upstream"]; upstream.LicenseDeclared != "MIT" {
+		t.Errorf("upstream package = %+v", upstream)
+	}
+
+	want := map[string]string{
+		"SPDXRef-Skill-review":         "SPDXRef-Project DEPENDS_ON",
+		"SPDXRef-Skill-notes":          "SPDXRef-Project CONTAINS",
+		"SPDXRef-Skill-notes-upstream": "SPDXRef-Skill-notes DESCENDANT_OF",
+		"SPDXRef-Project":              "SPDXRef-DOCUMENT DESCRIBES",
+	}
+	for _, rel := range doc.Relationships {
+		if got := rel.SPDXElementID + " " + rel.RelationshipType; want[rel.RelatedSPDXElement] != got {
+			t.Errorf("relationship to %s = %q, want %q", rel.RelatedSPDXElement, got, want[rel.RelatedSPDXElement])
+		}
+	}
+	if len(doc.Relationships) != len(want) {
+		t.Errorf("got %d relationships, want %d", len(doc.Relationships), len(want))
+	}
+}
+
+func TestGenerateIsReproducible(t *testing.T) {
+	for _, format := range []string{CycloneDX, SPDX} {
+		first, err := Generate(format, testDocument, testLock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, _ := Generate(format, testDocument, testLock)
+		if !bytes.Equal(first, second) {
+			t.Errorf("%s output differs between runs", format)
+		}
+		changed := &lock.Lock{Skills: append([]lock.Skill{}, testLock.Skills...)}
+		changed.Skills[0].Checksum = "dddd"
+		third, _ := Generate(format, testDocument, changed)
+		if bytes.Equal(first, third) {
+			t.Errorf("%s output did not change with the lock", format)
+		}
+	}
+	if _, err := Generate("swid", testDocument, testLock); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func hasProperty(props []cdxProperty, name, value string) bool {
+	for _, prop := range props {
+		if prop.Name == name && prop.Value == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,200 @@
+// Package skillmd parses and validates the YAML frontmatter of SKILL.md, the
+// name and description agents use to discover a skill.
+package skillmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// FileName is the file every skill directory must contain.
+const FileName = "SKILL.md"
+
+// Limits from the Agent Skills format.
+const (
+	MaxNameLength          = 64
+	MaxDescriptionLength   = 1024
+	MaxCompatibilityLength = 500
+)
+
+// knownKeys are the frontmatter keys the Agent Skills format defines.
+var knownKeys = map[string]bool{
+	"name":          true,
+	"description":   true,
+	"license":       true,
+	"compatibility": true,
+	"metadata":      true,
+	"allowed-tools": true,
+}
+
+// Frontmatter is the parsed YAML header of a SKILL.md file.
+type Frontmatter struct {
+	Name        string
+	Description string
+	// Fields holds every key as decoded, including Name and Description.
+	Fields map[string]any
+}
+
+// Problem is a single validation finding. Warnings do not make a skill
+// unusable; everything else does.
+type Problem struct {
+	Field   string
+	Message string
+	Warning bool
+}
+
+func (p Problem) String() string {
+	if p.Field == "" {
+		return p.Message
+	}
+	return fmt.Sprintf("%s: %s", p.Field, p.Message)
+}
+
+// Errors returns the problems that are not warnings.
+func Errors(problems []Problem) []Problem {
+	var errs []Problem
+	for _, p := range problems {
+		if !p.Warning {
+			errs = append(errs, p)
+		}
+	}
+	return errs
+}
+
+// Load parses the frontmatter of the SKILL.md in dir.
+func Load(dir string) (*Frontmatter, error) {
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse extracts and decodes the frontmatter block at the start of data. It
+// fails if the block is missing, unterminated, or not a YAML mapping.
+func Parse(data []byte) (*Frontmatter, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return nil, errors.New("missing frontmatter: SKILL.md must start with a --- line")
+	}
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if line := strings.TrimSpace(lines[i]); line == "---" || line == "..." {
+			end = i
+			break
+		}
+	}
+	if end == -1 {
+		return nil, errors.New("unterminated frontmatter: no closing --- line")
+	}
+
+	fields := map[string]any{}
+	if err := yaml.Unmarshal([]byte(strings.Join(lines[1:end], "")), &fields); err != nil {
+		return nil, fmt.Errorf("invalid frontmatter YAML: %w", err)
+	}
+	if fields == nil {
+		fields = map[string]any{}
+	}
+	fm := &Frontmatter{Fields: fields}
+	fm.Name, _ = fields["name"].(string)
+	fm.Description, _ = fields["description"].(string)
+	return fm, nil
+}
+
+// Validate checks fm against the Agent Skills format: name and description
+// are required strings within their length limits, name is lowercase letters,
+// digits and hyphens, and unknown keys are reported as warnings.
+func (fm *Frontmatter) Validate() []Problem {
+	var problems []Problem
+	add := func(field string, warning bool, format string, args ...any) {
+		problems = append(problems, Problem{Field: field, Message: fmt.Sprintf(format, args...), Warning: warning})
+	}
+
+	switch value, ok := fm.Fields["name"]; {
+	case !ok:
+		add("name", false, "required")
+	case !isString(value):
+		add("name", false, "must be a string")
+	default:
+		if err := ValidateName(fm.Name); err != nil {
+			add("name", false, "%v", err)
+		}
+	}
+
+	switch value, ok := fm.Fields["description"]; {
+	case !ok:
+		add("description", false, "required")
+	case !isString(value):
+		add("description", false, "must be a string")
+	case strings.TrimSpace(fm.Description) == "":
+		add("description", false, "must not be empty")
+	case utf8.RuneCountInString(fm.Description) > MaxDescriptionLength:
+		add("description", false, "longer than %d characters", MaxDescriptionLength)
+	}
+
+	for _, key := range []string{"license", "allowed-tools"} {
+		if value, ok := fm.Fields[key]; ok && !isString(value) {
+			add(key, false, "must be a string")
+		}
+	}
+	if value, ok := fm.Fields["compatibility"]; ok {
+		if !isString(value) {
+			add("compatibility", false, "must be a string")
+		} else if utf8.RuneCountInString(value.(string)) > MaxCompatibilityLength {
+			add("compatibility", false, "longer than %d characters", MaxCompatibilityLength)
+		}
+	}
+	if value, ok := fm.Fields["metadata"]; ok {
+		if _, isMap := value.(map[string]any); !isMap {
+			add("metadata", false, "must be a mapping")
+		}
+	}
+
+	var unknown []string
+	for key := range fm.Fields {
+		if !knownKeys[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		add(key, true, "unknown frontmatter key")
+	}
+	return problems
+}
+
+// ValidateName checks a skill name: 1 to MaxNameLength lowercase letters,
+// digits, and single hyphens, not starting or ending with a hyphen.
+func ValidateName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("must not be empty")
+	case len(name) > MaxNameLength:
+		return fmt.Errorf("longer than %d characters", MaxNameLength)
+	case strings.HasPrefix(name, "-") || strings.HasSuffix(name, "-"):
+		return fmt.Errorf("%q must not start or end with a hyphen", name)
+	case strings.Contains(name, "--"):
+		return fmt.Errorf("%q must not contain consecutive hyphens", name)
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return fmt.Errorf("%q may only contain lowercase letters, digits, and hyphens", name)
+		}
+	}
+	return nil
+}
+
+func isString(value any) bool {
+	_, ok := value.(string)
+	return ok
+}
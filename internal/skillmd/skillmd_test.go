@@ -0,0 +1,70 @@
+package skillmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	fm, err := Parse([]byte("\ufeff---\r\nname: review\r\ndescription: \"Reviews code\"\r\n---\r\n# Review\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if fm.Name != "review" || fm.Description != "Reviews code" {
+		t.Errorf("got name %q description %q", fm.Name, fm.Description)
+	}
+	if problems := fm.Validate(); len(problems) != 0 {
+		t.Errorf("unexpected problems: %v", problems)
+	}
+
+	cases := map[string]string{
+		"# Review\n":                 "missing frontmatter",
+		"---\nname: review\n":        "unterminated frontmatter",
+		"---\nname: [review\n---\n":  "invalid frontmatter YAML",
+		"---\n- name\n- review\n---": "invalid frontmatter YAML",
+	}
+	for input, want := range cases {
+		if _, err := Parse([]byte(input)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) error = %v, want %q", input, err, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		frontmatter string
+		want        []string
+	}{
+		{"name: review\ndescription: ok\nlicense: MIT\nmetadata:\n  author: me\n", nil},
+		{"", []string{"name: required", "description: required"}},
+		{"name: 42\ndescription: ok\n", []string{"name: must be a string"}},
+		{"name: Review\ndescription: ok\n", []string{`name: "Review" may only contain lowercase letters, digits, and hyphens`}},
+		{"name: -review\ndescription: ok\n", []string{`name: "-review" must not start or end with a hyphen`}},
+		{"name: acme--review\ndescription: ok\n", []string{`name: "acme--review" must not contain consecutive hyphens`}},
+		{"name: " + strings.Repeat("a", MaxNameLength+1) + "\ndescription: ok\n", []string{"name: longer than 64 characters"}},
+		{"name: review\ndescription: \"  \"\n", []string{"description: must not be empty"}},
+		{"name: review\ndescription: " + strings.Repeat("a", MaxDescriptionLength+1) + "\n", []string{"description: longer than 1024 characters"}},
+		{"name: review\ndescription: ok\nmetadata: yes\n", []string{"metadata: must be a mapping"}},
+		{"name: review\ndescription: ok\nversion: 2\nauthor: me\n", []string{"author: unknown frontmatter key", "version: unknown frontmatter key"}},
+	}
+	for _, tc := range cases {
+		fm, err := Parse([]byte("---\n" + tc.frontmatter + "---\n"))
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.frontmatter, err)
+		}
+		var got []string
+		for _, p := range fm.Validate() {
+			got = append(got, p.String())
+		}
+		if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+			t.Errorf("Validate(%q) = %q, want %q", tc.frontmatter, got, tc.want)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	problems := []Problem{{Field: "a", Message: "bad"}, {Field: "b", Message: "odd", Warning: true}}
+	if errs := Errors(problems); len(errs) != 1 || errs[0].Field != "a" {
+		t.Errorf("Errors = %v", errs)
+	}
+}
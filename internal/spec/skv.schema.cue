@@ -1,13 +1,108 @@
 // Schema for skv.cue files.
 
 #Spec: {
-	tools?: #Tools
+	tools?:    #Tools
+	checksum?: #Checksum
+	lint?:     #Lint
+	audit?:    #Audit
+	policy?:   #Policy
+	notices?:  #Notices
+	verify?:   #SpecVerify
+	// Namespace for every skill from a repo, keyed by repo URL.
+	namespaces?: [string]: #Namespace
 	skills: [...#Skill]
 	...
 }
 
+// Prefixes installed names as <namespace>--<name>.
+#Namespace: =~"^[a-zA-Z0-9][a-zA-Z0-9._-]*$"
+
 #Tools: {
 	exclude?: [...string]
+	define?: [...#ToolDef]
+	...
+}
+
+#ToolDef: {
+	name: string & !=""
+	// Skill directory relative to the repo root.
+	dir: string & !=""
+	link?: "symlink" | "copy" | "hardlink"
+	...
+}
+
+#Checksum: {
+	// "text" hashes text files with CRLF line endings normalized to LF.
+	normalize?: "text"
+	...
+}
+
+#Lint: {
+	// Rules skv lint skips.
+	disable?: [...#LintRule]
+	// Estimated tokens SKILL.md may use before token-budget fires (default 5000).
+	tokenBudget?: int & >0
+	...
+}
+
+#LintRule: "frontmatter" | "missing-description" | "name-mismatch" | "token-budget" |
+	"broken-link" | "missing-script" | "script-not-executable" | "non-utf8"
+
+#Audit: {
+	// Lowest severity that stops sync and update from vendoring a skill.
+	block?: #AuditSeverity
+	// Reviewed findings that skv audit and the sync check accept.
+	allow?: [...#AuditAllow]
+	...
+}
+
+#AuditSeverity: "low" | "medium" | "high"
+
+#AuditRule: "secret" | "pipe-to-shell" | "base64-blob" | "hidden-unicode" |
+	"prompt-injection" | "executable-binary"
+
+#AuditAllow: {
+	skill: string & !=""
+	rule:  #AuditRule
+	// File or directory, relative to the skill, the allowance is limited to.
+	path?:   string & !=""
+	reason?: string
+	...
+}
+
+#Policy: {
+	// Patterns remote skill repos must match; * matches within a path segment.
+	repos?: [...string & !=""]
+	// SPDX identifiers remote skills may be licensed under.
+	licenses?: [...string & !=""]
+	// Require remote skills to pin a commit unless their repo is trusted.
+	requirePinned?: bool
+	trusted?: [...string & !=""]
+	// Forbid vendored files with an executable bit.
+	noExecutables?: bool
+	...
+}
+
+#Verify: {
+	// "required" accepts only commits signed, or pointed at by a tag signed,
+	// with one of keys; "off" lifts a global requirement for one skill.
+	signatures?: "required" | "off"
+	// SSH public keys, or paths relative to skv.cue of SSH allowed-signers
+	// files or armored GPG public keys.
+	keys?: [...string & !=""]
+	...
+}
+
+#SpecVerify: {
+	#Verify
+	// SSH keys, in the same forms as keys, allowed to sign skv.lock. skv
+	// verify --require-signature ignores them, as a change can edit both.
+	lockKeys?: [...string & !=""]
+}
+
+#Notices: {
+	// File skv notices writes, or a directory of per-skill files if it ends in "/".
+	output?: string & !=""
 	...
 }
 
@@ -19,6 +114,10 @@
 	path?: string
 	ref?:  string
 	local?: ""
+	// Patch files (globs relative to skv.cue) applied in order after fetching.
+	patches?: [...string & !=""]
+	verify?: #Verify
+	#SkillOptions
 	...
 }
 
@@ -28,7 +127,23 @@
 	repo?: ""
 	path?: ""
 	ref?:  ""
+	patches?: []
+	#SkillOptions
 	...
 }
 
+#SkillOptions: {
+	// Link only into tools, or into all but excludeTools.
+	tools?: [...string]
+	excludeTools?: [...string]
+	namespace?: #Namespace
+	// Globs relative to the skill root; SKILL.md is always kept.
+	files?: #Files
+}
+
+#Files: {
+	include?: [...string & !=""]
+	exclude?: [...string & !=""]
+}
+
 skv: #Spec
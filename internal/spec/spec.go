@@ -4,6 +4,8 @@ import (
 	_ "embed"
 	"fmt"
 	"os"
+	"regexp"
+	"sort"
 	"strings"
 
 	"cuelang.org/go/cue"
@@ -15,11 +17,123 @@ var schemaData string
 
 // Spec mirrors the supported subset of skv.cue.
 type Spec struct {
-	Tools  *Tools       `json:"tools,omitempty"`
-	Skills []SkillEntry `json:"skills"`
+	Tools    *Tools    `json:"tools,omitempty"`
+	Checksum *Checksum `json:"checksum,omitempty"`
+	Lint     *Lint     `json:"lint,omitempty"`
+	Audit    *Audit    `json:"audit,omitempty"`
+	Policy   *Policy   `json:"policy,omitempty"`
+	Notices  *Notices  `json:"notices,omitempty"`
+	// Verify sets the signature requirement for every remote skill.
+	Verify *Verify `json:"verify,omitempty"`
+	// Namespaces maps a repo URL to the namespace of every skill from it.
+	Namespaces map[string]string `json:"namespaces,omitempty"`
+	Skills     []SkillEntry      `json:"skills"`
+}
+
+// NamespaceSeparator joins a namespace and a skill name.
+const NamespaceSeparator = "--"
+
+var namespacePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// ValidNamespace reports whether namespace is allowed by the schema.
+func ValidNamespace(namespace string) bool {
+	return namespacePattern.MatchString(namespace)
 }
 
 type Tools struct {
+	Exclude []string  `json:"exclude,omitempty"`
+	Define  []ToolDef `json:"define,omitempty"`
+}
+
+// ToolDef registers an agent skv links skills for, or overrides a built-in one.
+type ToolDef struct {
+	Name string `json:"name"`
+	Dir  string `json:"dir"`            // skill directory relative to the repo root
+	Link string `json:"link,omitempty"` // "symlink" (default), "copy", or "hardlink"
+}
+
+// Checksum configures how vendored content is hashed.
+type Checksum struct {
+	// Normalize is "" (byte-for-byte) or "text" (CRLF normalized to LF in text files).
+	Normalize string `json:"normalize,omitempty"`
+}
+
+// Lint configures skv lint.
+type Lint struct {
+	// Disable lists rules that are not run.
+	Disable []string `json:"disable,omitempty"`
+	// TokenBudget is the estimated token count SKILL.md may not exceed; 0
+	// selects the default.
+	TokenBudget int `json:"tokenBudget,omitempty"`
+}
+
+// Audit configures skv audit and the audit run by sync and update.
+type Audit struct {
+	// Block is the lowest severity ("low", "medium", or "high") that stops
+	// sync and update from vendoring a skill; "" disables the check.
+	Block string `json:"block,omitempty"`
+	// Allow lists findings that have been reviewed and accepted.
+	Allow []AuditAllow `json:"allow,omitempty"`
+}
+
+// AuditAllow accepts the findings of one rule in a skill, optionally limited
+// to a file or directory.
+type AuditAllow struct {
+	Skill  string `json:"skill"`
+	Rule   string `json:"rule"`
+	Path   string `json:"path,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Policy restricts where skills come from and what they may contain.
+type Policy struct {
+	// Repos are patterns remote skill repos must match, such as
+	// "github.com/acme/*"; empty allows any repo.
+	Repos []string `json:"repos,omitempty"`
+	// Licenses are the SPDX identifiers remote skills may be licensed under;
+	// empty allows any license.
+	Licenses []string `json:"licenses,omitempty"`
+	// RequirePinned requires remote skills to pin a commit unless their repo
+	// matches one of Trusted.
+	RequirePinned bool     `json:"requirePinned,omitempty"`
+	Trusted       []string `json:"trusted,omitempty"`
+	// NoExecutables forbids vendored files with an executable bit.
+	NoExecutables bool `json:"noExecutables,omitempty"`
+}
+
+// PolicyFile is the policy file read from next to skv.cue.
+const PolicyFile = "skv.policy.cue"
+
+// Notices configures skv notices.
+type Notices struct {
+	// Output is the notices file, or a directory of per-skill files when it
+	// ends in "/", relative to the spec; "" selects THIRD_PARTY_NOTICES.md.
+	Output string `json:"output,omitempty"`
+}
+
+// Signature requirements.
+const (
+	SignaturesRequired = "required"
+	SignaturesOff      = "off"
+)
+
+// Verify configures signature verification of remote skills.
+type Verify struct {
+	// Signatures is SignaturesRequired to accept only commits signed, or
+	// pointed at by a tag signed, by one of Keys; SignaturesOff lifts a
+	// requirement set for every skill.
+	Signatures string `json:"signatures,omitempty"`
+	// Keys are SSH public keys, or paths relative to the spec of SSH
+	// allowed-signers files or armored GPG public keys.
+	Keys []string `json:"keys,omitempty"`
+	// LockKeys are the SSH keys, in the same forms as Keys, allowed to sign
+	// the lock file. Only read at the spec level.
+	LockKeys []string `json:"lockKeys,omitempty"`
+}
+
+// FileFilter selects the files of a skill that are vendored, by glob.
+type FileFilter struct {
+	Include []string `json:"include,omitempty"`
 	Exclude []string `json:"exclude,omitempty"`
 }
 
@@ -29,6 +143,36 @@ type SkillEntry struct {
 	Path  string `json:"path,omitempty"`
 	Ref   string `json:"ref,omitempty"`
 	Local string `json:"local,omitempty"`
+
+	// Patches are globs, relative to the spec, of patch files applied in order
+	// on top of the fetched content of a remote skill.
+	Patches []string `json:"patches,omitempty"`
+
+	// Files limits which files of the skill are vendored.
+	Files *FileFilter `json:"files,omitempty"`
+
+	// Tools limits linking to the named tools; ExcludeTools skips them.
+	Tools        []string `json:"tools,omitempty"`
+	ExcludeTools []string `json:"excludeTools,omitempty"`
+
+	// Namespace prefixes the installed name; it overrides Spec.Namespaces.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Verify overrides Spec.Verify for this skill.
+	Verify *Verify `json:"verify,omitempty"`
+}
+
+// QualifiedName returns the name skill is vendored, locked and linked under:
+// its name prefixed with its namespace, if it has one.
+func (s *Spec) QualifiedName(skill SkillEntry) string {
+	namespace := skill.Namespace
+	if namespace == "" && skill.Repo != "" {
+		namespace = s.Namespaces[skill.Repo]
+	}
+	if namespace == "" {
+		return skill.Name
+	}
+	return namespace + NamespaceSeparator + skill.Name
 }
 
 func Load(path string) (*Spec, error) {
@@ -66,19 +210,151 @@ func Load(path string) (*Spec, error) {
 	return &spec, nil
 }
 
+// LoadPolicy returns the policy in effect for s: its policy block unified
+// with the policy field of each of files, so a shared policy can only be
+// repeated, never loosened. It returns nil if none of them sets a policy.
+func LoadPolicy(s *Spec, files ...string) (*Policy, error) {
+	ctx := cuecontext.New()
+	schema := ctx.CompileBytes([]byte(schemaData), cue.Filename("skv.schema.cue"))
+	if err := schema.Err(); err != nil {
+		return nil, err
+	}
+
+	v := schema.LookupPath(cue.ParsePath("#Policy"))
+	found := s.Policy != nil
+	if found {
+		v = v.Unify(ctx.Encode(s.Policy))
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		f := ctx.CompileBytes(data, cue.Filename(file))
+		if err := f.Err(); err != nil {
+			return nil, err
+		}
+		policyVal := f.LookupPath(cue.ParsePath("policy"))
+		if !policyVal.Exists() {
+			return nil, fmt.Errorf("%s: missing policy field", file)
+		}
+		v = v.Unify(policyVal)
+		found = true
+	}
+	if !found {
+		return nil, nil
+	}
+	if err := v.Validate(cue.Concrete(true)); err != nil {
+		return nil, err
+	}
+
+	var policy Policy
+	if err := v.Decode(&policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
 func Write(path string, spec *Spec) error {
 	var b strings.Builder
 	b.WriteString("skv: {\n")
-	if spec.Tools != nil && len(spec.Tools.Exclude) > 0 {
+	if spec.Tools != nil && (len(spec.Tools.Exclude) > 0 || len(spec.Tools.Define) > 0) {
 		b.WriteString("  tools: {\n")
-		b.WriteString("    exclude: [")
-		for i, tool := range spec.Tools.Exclude {
-			if i > 0 {
-				b.WriteString(", ")
+		if len(spec.Tools.Exclude) > 0 {
+			b.WriteString(fmt.Sprintf("    exclude: %s\n", quoteList(spec.Tools.Exclude)))
+		}
+		if len(spec.Tools.Define) > 0 {
+			b.WriteString("    define: [\n")
+			for _, tool := range spec.Tools.Define {
+				b.WriteString("      {\n")
+				b.WriteString(fmt.Sprintf("        name: %q\n", tool.Name))
+				b.WriteString(fmt.Sprintf("        dir: %q\n", tool.Dir))
+				if tool.Link != "" {
+					b.WriteString(fmt.Sprintf("        link: %q\n", tool.Link))
+				}
+				b.WriteString("      },\n")
+			}
+			b.WriteString("    ]\n")
+		}
+		b.WriteString("  }\n")
+	}
+	if spec.Checksum != nil && spec.Checksum.Normalize != "" {
+		b.WriteString("  checksum: {\n")
+		b.WriteString(fmt.Sprintf("    normalize: %q\n", spec.Checksum.Normalize))
+		b.WriteString("  }\n")
+	}
+	if spec.Lint != nil && (len(spec.Lint.Disable) > 0 || spec.Lint.TokenBudget > 0) {
+		b.WriteString("  lint: {\n")
+		if len(spec.Lint.Disable) > 0 {
+			b.WriteString(fmt.Sprintf("    disable: %s\n", quoteList(spec.Lint.Disable)))
+		}
+		if spec.Lint.TokenBudget > 0 {
+			b.WriteString(fmt.Sprintf("    tokenBudget: %d\n", spec.Lint.TokenBudget))
+		}
+		b.WriteString("  }\n")
+	}
+	if spec.Audit != nil && (spec.Audit.Block != "" || len(spec.Audit.Allow) > 0) {
+		b.WriteString("  audit: {\n")
+		if spec.Audit.Block != "" {
+			b.WriteString(fmt.Sprintf("    block: %q\n", spec.Audit.Block))
+		}
+		if len(spec.Audit.Allow) > 0 {
+			b.WriteString("    allow: [\n")
+			for _, allow := range spec.Audit.Allow {
+				b.WriteString("      {\n")
+				b.WriteString(fmt.Sprintf("        skill: %q\n", allow.Skill))
+				b.WriteString(fmt.Sprintf("        rule: %q\n", allow.Rule))
+				if allow.Path != "" {
+					b.WriteString(fmt.Sprintf("        path: %q\n", allow.Path))
+				}
+				if allow.Reason != "" {
+					b.WriteString(fmt.Sprintf("        reason: %q\n", allow.Reason))
+				}
+				b.WriteString("      },\n")
 			}
-			b.WriteString(fmt.Sprintf("%q", tool))
+			b.WriteString("    ]\n")
+		}
+		b.WriteString("  }\n")
+	}
+	if p := spec.Policy; p != nil && (len(p.Repos) > 0 || len(p.Licenses) > 0 || p.RequirePinned || len(p.Trusted) > 0 || p.NoExecutables) {
+		b.WriteString("  policy: {\n")
+		if len(p.Repos) > 0 {
+			b.WriteString(fmt.Sprintf("    repos: %s\n", quoteList(p.Repos)))
+		}
+		if len(p.Licenses) > 0 {
+			b.WriteString(fmt.Sprintf("    licenses: %s\n", quoteList(p.Licenses)))
+		}
+		if p.RequirePinned {
+			b.WriteString("    requirePinned: true\n")
+		}
+		if len(p.Trusted) > 0 {
+			b.WriteString(fmt.Sprintf("    trusted: %s\n", quoteList(p.Trusted)))
+		}
+		if p.NoExecutables {
+			b.WriteString("    noExecutables: true\n")
+		}
+		b.WriteString("  }\n")
+	}
+	if spec.Notices != nil && spec.Notices.Output != "" {
+		b.WriteString("  notices: {\n")
+		b.WriteString(fmt.Sprintf("    output: %q\n", spec.Notices.Output))
+		b.WriteString("  }\n")
+	}
+	if spec.Verify != nil && (spec.Verify.Signatures != "" || len(spec.Verify.Keys) > 0 || len(spec.Verify.LockKeys) > 0) {
+		b.WriteString("  verify: {\n")
+		writeVerify(&b, "    ", spec.Verify)
+		b.WriteString("  }\n")
+	}
+	if len(spec.Namespaces) > 0 {
+		repos := make([]string, 0, len(spec.Namespaces))
+		for repo := range spec.Namespaces {
+			repos = append(repos, repo)
+		}
+		sort.Strings(repos)
+		b.WriteString("  namespaces: {\n")
+		for _, repo := range repos {
+			b.WriteString(fmt.Sprintf("    %q: %q\n", repo, spec.Namespaces[repo]))
 		}
-		b.WriteString("]\n")
 		b.WriteString("  }\n")
 	}
 	b.WriteString("  skills: [\n")
@@ -97,6 +373,33 @@ func Write(path string, spec *Spec) error {
 		if skill.Local != "" {
 			b.WriteString(fmt.Sprintf("      local: %q\n", skill.Local))
 		}
+		if len(skill.Patches) > 0 {
+			b.WriteString(fmt.Sprintf("      patches: %s\n", quoteList(skill.Patches)))
+		}
+		if skill.Files != nil && (len(skill.Files.Include) > 0 || len(skill.Files.Exclude) > 0) {
+			b.WriteString("      files: {\n")
+			if len(skill.Files.Include) > 0 {
+				b.WriteString(fmt.Sprintf("        include: %s\n", quoteList(skill.Files.Include)))
+			}
+			if len(skill.Files.Exclude) > 0 {
+				b.WriteString(fmt.Sprintf("        exclude: %s\n", quoteList(skill.Files.Exclude)))
+			}
+			b.WriteString("      }\n")
+		}
+		if skill.Namespace != "" {
+			b.WriteString(fmt.Sprintf("      namespace: %q\n", skill.Namespace))
+		}
+		if len(skill.Tools) > 0 {
+			b.WriteString(fmt.Sprintf("      tools: %s\n", quoteList(skill.Tools)))
+		}
+		if len(skill.ExcludeTools) > 0 {
+			b.WriteString(fmt.Sprintf("      excludeTools: %s\n", quoteList(skill.ExcludeTools)))
+		}
+		if skill.Verify != nil && (skill.Verify.Signatures != "" || len(skill.Verify.Keys) > 0) {
+			b.WriteString("      verify: {\n")
+			writeVerify(&b, "        ", skill.Verify)
+			b.WriteString("      }\n")
+		}
 		b.WriteString("    },\n")
 	}
 	b.WriteString("  ]\n")
@@ -105,6 +408,27 @@ func Write(path string, spec *Spec) error {
 	return os.WriteFile(path, []byte(b.String()), 0o644)
 }
 
+func writeVerify(b *strings.Builder, indent string, v *Verify) {
+	if v.Signatures != "" {
+		b.WriteString(fmt.Sprintf("%ssignatures: %q\n", indent, v.Signatures))
+	}
+	if len(v.Keys) > 0 {
+		b.WriteString(fmt.Sprintf("%skeys: %s\n", indent, quoteList(v.Keys)))
+	}
+	if len(v.LockKeys) > 0 {
+		b.WriteString(fmt.Sprintf("%slockKeys: %s\n", indent, quoteList(v.LockKeys)))
+	}
+}
+
+// quoteList formats items as a CUE list of strings.
+func quoteList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = fmt.Sprintf("%q", item)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
 func AppendSkill(path string, entry SkillEntry) error {
 	spec, err := Load(path)
 	if err != nil {
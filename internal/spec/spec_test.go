@@ -1,8 +1,10 @@
 package spec
 
 import (
+	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -13,17 +15,59 @@ func TestWriteLoadRoundTrip(t *testing.T) {
 	original := &Spec{
 		Tools: &Tools{
 			Exclude: []string{"opencode"},
+			Define: []ToolDef{
+				{Name: "cursor", Dir: ".cursor/skills", Link: "symlink"},
+				{Name: "goose", Dir: ".goose/skills"},
+			},
+		},
+		Checksum: &Checksum{
+			Normalize: "text",
+		},
+		Lint: &Lint{
+			Disable:     []string{"token-budget", "non-utf8"},
+			TokenBudget: 8000,
+		},
+		Audit: &Audit{
+			Block: "high",
+			Allow: []AuditAllow{
+				{Skill: "skill-foo", Rule: "base64-blob", Path: "assets", Reason: "embedded icons"},
+				{Skill: "local-bar", Rule: "prompt-injection"},
+			},
+		},
+		Policy: &Policy{
+			Repos:         []string{"example.com/*"},
+			Licenses:      []string{"MIT", "Apache-2.0"},
+			RequirePinned: true,
+			Trusted:       []string{"example.com/skill-pack"},
+			NoExecutables: true,
+		},
+		Notices: &Notices{
+			Output: "licenses/",
+		},
+		Verify: &Verify{
+			Signatures: SignaturesRequired,
+			Keys:       []string{"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIExample release", "keys/release.asc"},
+			LockKeys:   []string{"keys/maintainers"},
+		},
+		Namespaces: map[string]string{
+			"https://example.com/skill-pack": "acme",
 		},
 		Skills: []SkillEntry{
 			{
-				Name: "skill-foo",
-				Repo: "https://example.com/skill-pack",
-				Path: "skills/skill-foo",
-				Ref:  "main",
+				Name:    "skill-foo",
+				Repo:    "https://example.com/skill-pack",
+				Path:    "skills/skill-foo",
+				Ref:     "main",
+				Patches: []string{"patches/skill-foo/*.patch"},
+				Files:   &FileFilter{Include: []string{"SKILL.md", "scripts"}, Exclude: []string{"*.png"}},
+				Tools:   []string{"claude"},
+				Verify:  &Verify{Signatures: SignaturesOff},
 			},
 			{
-				Name:  "local-bar",
-				Local: "./.skv/skills/local-bar",
+				Name:         "local-bar",
+				Local:        "./.skv/skills/local-bar",
+				ExcludeTools: []string{"codex", "opencode"},
+				Namespace:    "team",
 			},
 		},
 	}
@@ -41,3 +85,59 @@ func TestWriteLoadRoundTrip(t *testing.T) {
 		t.Fatalf("round-trip mismatch: %#v vs %#v", original, loaded)
 	}
 }
+
+func TestQualifiedName(t *testing.T) {
+	spec := &Spec{Namespaces: map[string]string{"https://example.com/acme": "acme"}}
+
+	cases := []struct {
+		skill SkillEntry
+		want  string
+	}{
+		{SkillEntry{Name: "code-review", Repo: "https://example.com/other"}, "code-review"},
+		{SkillEntry{Name: "code-review", Repo: "https://example.com/acme"}, "acme--code-review"},
+		{SkillEntry{Name: "code-review", Repo: "https://example.com/acme", Namespace: "mine"}, "mine--code-review"},
+		{SkillEntry{Name: "helper", Local: "./helper", Namespace: "team"}, "team--helper"},
+	}
+	for _, tc := range cases {
+		if got := spec.QualifiedName(tc.skill); got != tc.want {
+			t.Errorf("QualifiedName(%+v) = %q, want %q", tc.skill, got, tc.want)
+		}
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	tmp := t.TempDir()
+	shared := filepath.Join(tmp, "shared.cue")
+	if err := os.WriteFile(shared, []byte(`policy: {
+  licenses: ["MIT"]
+  noExecutables: true
+}
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if policy, err := LoadPolicy(&Spec{}); err != nil || policy != nil {
+		t.Fatalf("LoadPolicy without policy = %v, %v", policy, err)
+	}
+
+	policy, err := LoadPolicy(&Spec{Policy: &Policy{Repos: []string{"example.com/*"}, Licenses: []string{"MIT"}}}, shared)
+	if err != nil {
+		t.Fatalf("LoadPolicy: %v", err)
+	}
+	want := &Policy{Repos: []string{"example.com/*"}, Licenses: []string{"MIT"}, NoExecutables: true}
+	if !reflect.DeepEqual(policy, want) {
+		t.Errorf("LoadPolicy = %#v, want %#v", policy, want)
+	}
+
+	if _, err := LoadPolicy(&Spec{Policy: &Policy{Licenses: []string{"MIT", "GPL-3.0"}}}, shared); err == nil {
+		t.Error("LoadPolicy accepted a spec loosening the shared license list")
+	}
+
+	invalid := filepath.Join(tmp, "invalid.cue")
+	if err := os.WriteFile(invalid, []byte(`policy: requirePinned: "yes"`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPolicy(&Spec{}, invalid); err == nil || !strings.Contains(err.Error(), "requirePinned") {
+		t.Errorf("LoadPolicy(invalid) error = %v", err)
+	}
+}
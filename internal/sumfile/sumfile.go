@@ -0,0 +1,118 @@
+// Package sumfile reads and writes skv.sum, an append-only record of the
+// checksum of every (repo, commit, path) skv has fetched, so a commit whose
+// content changes between fetches is caught.
+package sumfile
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Key identifies fetched content: a skill directory at a commit.
+type Key struct {
+	Repo   string
+	Commit string
+	Path   string // slash-separated; empty for the repo root
+}
+
+func (k Key) String() string {
+	path := k.Path
+	if path == "" {
+		path = "."
+	}
+	return fmt.Sprintf("%s %s %s", k.Repo, k.Commit, path)
+}
+
+// File is the parsed contents of a sum file.
+type File struct {
+	sums map[Key]string
+}
+
+// Mismatch reports content whose checksum differs from the one recorded.
+type Mismatch struct {
+	Key      Key
+	Recorded string
+	Got      string
+}
+
+func (m *Mismatch) Error() string {
+	return fmt.Sprintf("%s hashes to %s, but skv.sum recorded %s", m.Key, m.Got, m.Recorded)
+}
+
+// Load reads the sum file at path. A missing file is empty. A file that
+// records two checksums for the same key, as a merge of two diverging views
+// can, is an error.
+func Load(path string) (*File, error) {
+	f := &File{sums: map[Key]string{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("%s:%d: want <repo> <commit> <path> <checksum>", path, n)
+		}
+		key := Key{Repo: fields[0], Commit: fields[1], Path: fields[2]}
+		if key.Path == "." {
+			key.Path = ""
+		}
+		if recorded, ok := f.sums[key]; ok && recorded != fields[3] {
+			return nil, fmt.Errorf("%s:%d: %s is recorded with two checksums, %s and %s", path, n, key, recorded, fields[3])
+		}
+		f.sums[key] = fields[3]
+	}
+	return f, scanner.Err()
+}
+
+// Lookup returns the checksum recorded for key.
+func (f *File) Lookup(key Key) (string, bool) {
+	checksum, ok := f.sums[key]
+	return checksum, ok
+}
+
+// Check compares checksum with the one recorded for key, if any. It returns
+// a *Mismatch when they differ.
+func (f *File) Check(key Key, checksum string) error {
+	if recorded, ok := f.sums[key]; ok && recorded != checksum {
+		return &Mismatch{Key: key, Recorded: recorded, Got: checksum}
+	}
+	return nil
+}
+
+// Append adds a line recording checksum for key to the end of the sum file
+// at path, creating it if needed. Existing lines are left as they are, so the
+// file only ever grows; Lookup the key first to avoid recording it twice.
+func Append(path string, key Key, checksum string) error {
+	if strings.ContainsAny(key.Repo+key.Commit+key.Path+checksum, " \t\n") {
+		return fmt.Errorf("cannot record %q in skv.sum: it contains whitespace", key.String())
+	}
+	line := key.String() + " " + checksum + "\n"
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		line = "\n" + line
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
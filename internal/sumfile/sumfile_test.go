@@ -0,0 +1,77 @@
+package sumfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "skv.sum")
+	f, err := Load(path)
+	if err != nil {
+		t.Fatalf("load missing file: %v", err)
+	}
+	if _, ok := f.Lookup(Key{Repo: "https://example.com/b", Commit: "c2"}); ok {
+		t.Fatal("Lookup found a key in an empty file")
+	}
+	root := Key{Repo: "https://example.com/b", Commit: "c2"}
+	sub := Key{Repo: "https://example.com/a", Commit: "c1", Path: "skills/review"}
+	if err := Append(path, root, "text:bbbb"); err != nil {
+		t.Fatal(err)
+	}
+	// Lines are never reordered, and one without a final newline is kept.
+	data, _ := os.ReadFile(path)
+	if err := os.WriteFile(path, append(data, "# kept"...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Append(path, sub, "none:aaaa"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ = os.ReadFile(path)
+	want := "https://example.com/b c2 . text:bbbb\n# kept\nhttps://example.com/a c1 skills/review none:aaaa\n"
+	if string(data) != want {
+		t.Fatalf("skv.sum = %q, want %q", data, want)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := loaded.Lookup(sub); !ok || got != "none:aaaa" {
+		t.Errorf("Lookup(sub) = %q, %v", got, ok)
+	}
+	if err := loaded.Check(root, "text:bbbb"); err != nil {
+		t.Errorf("Check(root) = %v", err)
+	}
+	if err := loaded.Check(Key{Repo: "https://example.com/a", Commit: "c9"}, "none:zzzz"); err != nil {
+		t.Errorf("Check(unknown) = %v", err)
+	}
+	var mismatch *Mismatch
+	if err := loaded.Check(sub, "none:dddd"); !errors.As(err, &mismatch) || mismatch.Recorded != "none:aaaa" || mismatch.Got != "none:dddd" {
+		t.Errorf("Check(changed) = %v", err)
+	}
+	if err := Append(path, Key{Repo: "https://example.com/a b", Commit: "c1"}, "none:aaaa"); err == nil {
+		t.Error("expected an error for a key with whitespace")
+	}
+}
+
+func TestLoadRejectsDivergentLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "skv.sum")
+	data := "https://example.com/a c1 . aaaa\nhttps://example.com/a c1 . aaaa\nhttps://example.com/a c1 . bbbb\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "two checksums, aaaa and bbbb") {
+		t.Fatalf("Load = %v, want a conflict", err)
+	}
+
+	if err := os.WriteFile(path, []byte("https://example.com/a c1 aaaa\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected an error for a malformed line")
+	}
+}